@@ -134,6 +134,8 @@ func (c *Client) NewBaseRequest(ctx context.Context, method, url string, session
 	return req, nil
 }
 
+// GetErr converts error responses(either the default error body or problem details)
+// to api.Err. It returns nil for successful responses.
 func GetErr(res *http.Response) error {
 	if res.StatusCode < 400 {
 		return nil
@@ -143,6 +145,11 @@ func GetErr(res *http.Response) error {
 	if err := json.NewDecoder(res.Body).Decode(&err); err != nil {
 		return err
 	}
+
+	if err.Code == 0 {
+		err.Code = res.StatusCode
+	}
+
 	return err
 }
 
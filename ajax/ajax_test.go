@@ -3,12 +3,14 @@ package ajax
 import (
 	"context"
 	"errors"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/noxecane/anansi/api"
 	"github.com/noxecane/anansi/jwt"
 	"github.com/segmentio/ksuid"
 	"syreclabs.com/go/faker"
@@ -244,3 +246,39 @@ func TestNewBaseRequest(t *testing.T) {
 		}
 	})
 }
+
+func TestGetErr(t *testing.T) {
+	message := faker.Lorem().Sentence(3)
+	bodies := map[string]string{
+		"default":         `{"message": "` + message + `", "data": {"name": "yuko"}}`,
+		"problem details": `{"type": "about:blank", "title": "Conflict", "status": 409, "detail": "` + message + `", "data": {"name": "yuko"}}`,
+	}
+
+	for name, body := range bodies {
+		t.Run("parses "+name+" error body", func(t *testing.T) {
+			res := &http.Response{
+				StatusCode: http.StatusConflict,
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}
+
+			err := GetErr(res)
+
+			var e api.Err
+			if !errors.As(err, &e) {
+				t.Fatalf("Expected GetErr to return api.Err, got %v", err)
+			}
+
+			if e.Code != http.StatusConflict {
+				t.Errorf("Expected the status code to be %d, got %d", http.StatusConflict, e.Code)
+			}
+
+			if e.Message != message {
+				t.Errorf("Expected error message to be %s, got %s", message, e.Message)
+			}
+
+			if e.Data == nil {
+				t.Error("Expected error data to be set")
+			}
+		})
+	}
+}
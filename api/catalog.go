@@ -0,0 +1,102 @@
+package api
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+var (
+	catalogMu sync.RWMutex
+	catalog   = make(map[string]Err)
+)
+
+// Definition describes a registered error code for API docs
+type Definition struct {
+	Kind    string `json:"code"`
+	Status  int    `json:"status"`
+	Message string `json:"message"`
+}
+
+// Define registers an error with a stable machine readable code, which is sent as
+// the "code" of every response built from it. Define it once at the package level
+// and wrap the underlying errors with Err.Wrap. It panics if the code has already
+// been defined.
+//
+//	var ErrBookNotFound = api.Define("book_not_found", 404, "We could not find your book")
+//
+//	panic(ErrBookNotFound.Wrap(err))
+func Define(kind string, status int, message string) Err {
+	catalogMu.Lock()
+	defer catalogMu.Unlock()
+
+	if _, ok := catalog[kind]; ok {
+		panic(fmt.Errorf("error code %s has already been defined", kind))
+	}
+
+	e := Err{Code: status, Message: message, Kind: kind}
+	catalog[kind] = e
+
+	return e
+}
+
+// Catalog lists every error code registered with Define, sorted by the code.
+func Catalog() []Definition {
+	catalogMu.RLock()
+	defer catalogMu.RUnlock()
+
+	defs := make([]Definition, 0, len(catalog))
+	for _, e := range catalog {
+		defs = append(defs, Definition{Kind: e.Kind, Status: e.Code, Message: e.Message})
+	}
+
+	sort.Slice(defs, func(i, j int) bool {
+		return defs[i].Kind < defs[j].Kind
+	})
+
+	return defs
+}
+
+// ServeCatalog responds with the list of registered error codes
+func ServeCatalog(w http.ResponseWriter, r *http.Request) {
+	Success(r, w, Catalog())
+}
+
+// Wrap creates a copy of the error with err as its source
+func (e Err) Wrap(err error) Err {
+	e.Err = err
+	return e
+}
+
+// WithData creates a copy of the error with the given metadata
+func (e Err) WithData(data interface{}) Err {
+	e.Data = data
+	return e
+}
+
+// Is reports whether target is an Err with the same code, making it possible to
+// check wrapped errors against those created with Define.
+func (e Err) Is(target error) bool {
+	t, ok := target.(Err)
+	if !ok || t.Kind == "" {
+		return false
+	}
+
+	return e.Kind == t.Kind
+}
+
+// statusKind is the code of errors that were not created with Define, derived
+// from the status code(e.g. not_found for 404).
+func statusKind(status int) string {
+	text := strings.ToLower(http.StatusText(status))
+	if text == "" {
+		return "unknown_error"
+	}
+
+	text = strings.ReplaceAll(text, "'", "")
+	text = strings.ReplaceAll(text, "-", "_")
+
+	return strings.ReplaceAll(text, " ", "_")
+}
@@ -0,0 +1,103 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/noxecane/anansi/json"
+)
+
+var errBookNotFound = Define("book_not_found", http.StatusNotFound, "We could not find your book")
+
+func TestDefine(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(Recoverer("production"))
+	router.Get("/defined", func(_ http.ResponseWriter, _ *http.Request) {
+		panic(errBookNotFound.Wrap(errors.New("no rows")))
+	})
+	router.Get("/undefined", func(_ http.ResponseWriter, _ *http.Request) {
+		panic(Err{Code: http.StatusConflict, Message: "Book already exists"})
+	})
+
+	t.Run("panics when code is defined twice", func(t *testing.T) {
+		defer func() {
+			if err := recover(); err == nil {
+				t.Error("Expected Define to panic")
+			}
+		}()
+
+		Define("book_not_found", http.StatusNotFound, "")
+	})
+
+	t.Run("wrapped errors match their definition", func(t *testing.T) {
+		var err error = errBookNotFound.Wrap(errors.New("no rows"))
+
+		if !errors.Is(err, errBookNotFound) {
+			t.Error("Expected wrapped error to match its definition")
+		}
+
+		if errors.Is(err, Err{Kind: "author_not_found"}) {
+			t.Error("Expected wrapped error not to match other definitions")
+		}
+	})
+
+	t.Run("sends the code of defined errors", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/defined", nil)
+		router.ServeHTTP(res, req)
+
+		var e Err
+		if err := json.Unmarshal(res.Body.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+
+		if res.Code != http.StatusNotFound {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusNotFound, res.Code)
+		}
+
+		if e.Kind != "book_not_found" {
+			t.Errorf("Expected the code to be book_not_found, got %s", e.Kind)
+		}
+	})
+
+	t.Run("sends a code derived from the status", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/undefined", nil)
+		router.ServeHTTP(res, req)
+
+		var e Err
+		if err := json.Unmarshal(res.Body.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+
+		if e.Kind != "conflict" {
+			t.Errorf("Expected the code to be conflict, got %s", e.Kind)
+		}
+	})
+}
+
+func TestCatalog(t *testing.T) {
+	defs := Catalog()
+
+	var found bool
+	for i, d := range defs {
+		if i > 0 && defs[i-1].Kind > d.Kind {
+			t.Errorf("Expected catalog to be sorted, got %s before %s", defs[i-1].Kind, d.Kind)
+		}
+
+		if d.Kind == errBookNotFound.Kind {
+			found = true
+
+			if d.Status != http.StatusNotFound {
+				t.Errorf("Expected the status of %s to be %d, got %d", d.Kind, http.StatusNotFound, d.Status)
+			}
+		}
+	}
+
+	if !found {
+		t.Errorf("Expected %s to be in the catalog", errBookNotFound.Kind)
+	}
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"reflect"
+)
+
+// JSON creates a handler that reads the JSON body of the request into In(applying
+// mold transformations and ozzo validation), calls fn and sends Out. The response is a
+// 201 for POST requests, a 204 when Out is empty(struct{} or a nil pointer) and a 200
+// otherwise. Errors returned by fn are handled like HandlerFunc. Use chi.URLParamFromCtx
+// to access URL params in fn.
+func JSON[In, Out any](fn func(context.Context, In) (Out, error)) http.HandlerFunc {
+	return Handle(func(w http.ResponseWriter, r *http.Request) error {
+		var in In
+		if err := TryReadJSON(r, &in); err != nil {
+			return err
+		}
+
+		return reply(w, r, fn, in)
+	})
+}
+
+// Query is JSON for handlers whose input comes from the query parameters of the
+// request.
+func Query[In, Out any](fn func(context.Context, In) (Out, error)) http.HandlerFunc {
+	return Handle(func(w http.ResponseWriter, r *http.Request) error {
+		var in In
+		if err := TryQueryParam(r, &in); err != nil {
+			return err
+		}
+
+		return reply(w, r, fn, in)
+	})
+}
+
+func reply[In, Out any](w http.ResponseWriter, r *http.Request, fn func(context.Context, In) (Out, error), in In) error {
+	out, err := fn(r.Context(), in)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case isEmpty(out):
+		NoContent(r, w)
+	case r.Method == http.MethodPost:
+		Send(r, w, http.StatusCreated, out)
+	default:
+		Send(r, w, http.StatusOK, out)
+	}
+
+	return nil
+}
+
+// isEmpty checks if there's nothing worth sending in v
+func isEmpty(v any) bool {
+	if v == nil {
+		return true
+	}
+
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Interface:
+		return rv.IsNil()
+	default:
+		return rv.Type().Size() == 0
+	}
+}
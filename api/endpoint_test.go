@@ -0,0 +1,138 @@
+package api
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	ozzo "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/noxecane/anansi/json"
+)
+
+type bookQuery struct {
+	Title string `json:"title" mod:"trim"`
+}
+
+func (q *bookQuery) Validate() error {
+	return ozzo.ValidateStruct(q,
+		ozzo.Field(&q.Title, ozzo.Required),
+	)
+}
+
+func TestJSON(t *testing.T) {
+	router := chi.NewRouter()
+
+	router.Post("/books", JSON(func(_ context.Context, in myStruct) (myStruct, error) {
+		return in, nil
+	}))
+	router.Put("/books", JSON(func(_ context.Context, in myStruct) (myStruct, error) {
+		return in, nil
+	}))
+	router.Delete("/books", JSON(func(_ context.Context, _ myStruct) (struct{}, error) {
+		return struct{}{}, nil
+	}))
+	router.Patch("/books", JSON(func(_ context.Context, _ myStruct) (*myStruct, error) {
+		return nil, errBookNotFound
+	}))
+
+	send := func(method, body string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/books", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(res, req)
+
+		return res
+	}
+
+	t.Run("responds with 201 for POST", func(t *testing.T) {
+		res := send("POST", `{"name": "Things fall apart"}`)
+
+		if res.Code != http.StatusCreated {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusCreated, res.Code)
+		}
+
+		var m myStruct
+		if err := json.Unmarshal(res.Body.Bytes(), &m); err != nil {
+			t.Fatal(err)
+		}
+
+		if m.Name != "Things fall apart" {
+			t.Errorf("Expected the name to be Things fall apart, got %s", m.Name)
+		}
+	})
+
+	t.Run("responds with 200 for other methods", func(t *testing.T) {
+		res := send("PUT", `{"name": "Things fall apart"}`)
+
+		if res.Code != http.StatusOK {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusOK, res.Code)
+		}
+	})
+
+	t.Run("responds with 204 for empty responses", func(t *testing.T) {
+		res := send("DELETE", `{"name": "Things fall apart"}`)
+
+		if res.Code != http.StatusNoContent {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusNoContent, res.Code)
+		}
+
+		if res.Body.Len() != 0 {
+			t.Errorf("Expected the body to be empty, got %s", res.Body.String())
+		}
+	})
+
+	t.Run("responds with 400 for invalid input", func(t *testing.T) {
+		res := send("POST", `{}`)
+
+		if res.Code != http.StatusBadRequest {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusBadRequest, res.Code)
+		}
+	})
+
+	t.Run("responds with returned errors", func(t *testing.T) {
+		res := send("PATCH", `{"name": "Things fall apart"}`)
+
+		if res.Code != http.StatusNotFound {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusNotFound, res.Code)
+		}
+	})
+}
+
+func TestQuery(t *testing.T) {
+	router := chi.NewRouter()
+	router.Get("/books", Query(func(_ context.Context, in bookQuery) ([]bookQuery, error) {
+		return []bookQuery{in}, nil
+	}))
+
+	t.Run("reads input from the query", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/books?title=%20Arrow%20of%20God", nil)
+		router.ServeHTTP(res, req)
+
+		if res.Code != http.StatusOK {
+			t.Fatalf("Expected the status code to be %d, got %d", http.StatusOK, res.Code)
+		}
+
+		var books []bookQuery
+		if err := json.Unmarshal(res.Body.Bytes(), &books); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(books) != 1 || books[0].Title != "Arrow of God" {
+			t.Errorf("Expected the trimmed title to be sent back, got %v", books)
+		}
+	})
+
+	t.Run("responds with 400 for invalid input", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/books", nil)
+		router.ServeHTTP(res, req)
+
+		if res.Code != http.StatusBadRequest {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusBadRequest, res.Code)
+		}
+	})
+}
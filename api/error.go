@@ -1,6 +1,11 @@
 package api
 
-import "fmt"
+import (
+	"encoding/xml"
+	"fmt"
+
+	"github.com/noxecane/anansi/json"
+)
 
 // Err defines the structure of an HTTP error response
 type Err struct {
@@ -8,6 +13,14 @@ type Err struct {
 	Message string      `json:"message"`
 	Data    interface{} `json:"data"`
 	Err     error       `json:"-"`
+	// Kind is the stable machine readable code of the error, set by Define.
+	// It's derived from the status code when empty.
+	Kind string `json:"code"`
+	// Type is a URI identifying the kind of problem. It's only sent with
+	// problem details and defaults to "about:blank"
+	Type string `json:"-"`
+	// Extensions are extra members added to problem details
+	Extensions map[string]interface{} `json:"-"`
 }
 
 func (e Err) Error() string {
@@ -19,3 +32,37 @@ func (e Err) Error() string {
 }
 
 func (e Err) Unwrap() error { return e.Err }
+
+// MarshalXML encodes the error as an <error> element for clients that prefer XML.
+func (e Err) MarshalXML(enc *xml.Encoder, _ xml.StartElement) error {
+	return enc.Encode(struct {
+		XMLName xml.Name    `xml:"error"`
+		Kind    string      `xml:"code"`
+		Message string      `xml:"message"`
+		Data    interface{} `xml:"data,omitempty"`
+	}{Kind: e.Kind, Message: e.Message, Data: e.Data})
+}
+
+// UnmarshalJSON decodes both the default error body and problem details
+// into the Err.
+func (e *Err) UnmarshalJSON(b []byte) error {
+	var members map[string]interface{}
+	if err := json.Unmarshal(b, &members); err != nil {
+		return err
+	}
+
+	_, hasDetail := members["detail"]
+	_, hasTitle := members["title"]
+	if !hasDetail && !hasTitle {
+		type plain Err
+		return json.Unmarshal(b, (*plain)(e))
+	}
+
+	var p Problem
+	if err := json.Unmarshal(b, &p); err != nil {
+		return err
+	}
+
+	*e = p.Err()
+	return nil
+}
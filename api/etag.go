@@ -0,0 +1,172 @@
+package api
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/noxecane/anansi/json"
+)
+
+// ETagMode controls how Success computes ETags from response bodies
+type ETagMode int
+
+const (
+	// NoETag leaves responses without an ETag unless the handler sets one
+	NoETag ETagMode = iota
+	// StrongETag computes ETags that change with every byte of the body
+	StrongETag
+	// WeakETag computes ETags marked as weak(W/) validators
+	WeakETag
+)
+
+// ETags is the ETagMode used by every router. Use the WithETags middleware to
+// change it for a single router instead.
+var ETags = NoETag
+
+type etagKey struct{}
+
+// WithETags is a middleware that sets the ETagMode for the routes it's used on.
+func WithETags(mode ETagMode) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), etagKey{}, mode)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// ETag computes the ETag of the JSON of v the same way Success does, so handlers can check
+// If-Match against the current state of a resource using IfMatch.
+func ETag(v interface{}, mode ETagMode) string {
+	raw, _ := json.Marshal(v)
+	return bodyETag(raw, mode)
+}
+
+// IfMatch checks the If-Match header of the request against the current ETag of the
+// resource before it gets changed. It panics with a 412 if the header is set and
+// none of its ETags match.
+func IfMatch(r *http.Request, current string) {
+	if err := TryIfMatch(r, current); err != nil {
+		panic(err)
+	}
+}
+
+// TryIfMatch is IfMatch that returns the Err rather than panic.
+func TryIfMatch(r *http.Request, current string) error {
+	header := r.Header.Get("If-Match")
+	if header == "" || matchETag(header, current, false) {
+		return nil
+	}
+
+	return Err{
+		Code:    http.StatusPreconditionFailed,
+		Message: "The resource has been changed since you last fetched it.",
+	}
+}
+
+// LastModified sets the Last-Modified header of the response so Success can answer
+// If-Modified-Since with a 304. Use it before calling Success.
+func LastModified(w http.ResponseWriter, t time.Time) {
+	w.Header().Set("Last-Modified", t.UTC().Format(http.TimeFormat))
+}
+
+// IfUnmodifiedSince checks the If-Unmodified-Since header of the request against the time
+// the resource was last changed, before it gets changed again. It panics with a 412 if the
+// resource has changed since then. The header is ignored when the request has If-Match.
+func IfUnmodifiedSince(r *http.Request, modified time.Time) {
+	if err := TryIfUnmodifiedSince(r, modified); err != nil {
+		panic(err)
+	}
+}
+
+// TryIfUnmodifiedSince is IfUnmodifiedSince that returns the Err rather than panic.
+func TryIfUnmodifiedSince(r *http.Request, modified time.Time) error {
+	if r.Header.Get("If-Match") != "" {
+		return nil
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Unmodified-Since"))
+	if err != nil || !modified.Truncate(time.Second).After(since) {
+		return nil
+	}
+
+	return Err{
+		Code:    http.StatusPreconditionFailed,
+		Message: "The resource has been changed since you last fetched it.",
+	}
+}
+
+// notModified sets the ETag of the response(unless the handler already did), and
+// checks if the client's copy of the response is still fresh, using If-None-Match or
+// If-Modified-Since when the handler set Last-Modified.
+func notModified(r *http.Request, w http.ResponseWriter, raw []byte) bool {
+	etag := w.Header().Get("ETag")
+	if etag == "" {
+		mode := ETags
+		if m, ok := r.Context().Value(etagKey{}).(ETagMode); ok {
+			mode = m
+		}
+
+		if mode != NoETag {
+			etag = bodyETag(raw, mode)
+			w.Header().Set("ETag", etag)
+		}
+	}
+
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		return false
+	}
+
+	// If-Modified-Since is ignored when the client sends If-None-Match
+	if header := r.Header.Get("If-None-Match"); header != "" {
+		return etag != "" && matchETag(header, etag, true)
+	}
+
+	since, err := http.ParseTime(r.Header.Get("If-Modified-Since"))
+	if err != nil {
+		return false
+	}
+
+	modified, err := http.ParseTime(w.Header().Get("Last-Modified"))
+	return err == nil && !modified.After(since)
+}
+
+func bodyETag(raw []byte, mode ETagMode) string {
+	sum := sha256.Sum256(raw)
+	etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+
+	if mode == WeakETag {
+		return "W/" + etag
+	}
+
+	return etag
+}
+
+// matchETag checks if etag is in the comma separated list from a conditional header,
+// using the weak comparison for If-None-Match and strong comparison for If-Match.
+func matchETag(header, etag string, weak bool) bool {
+	if strings.TrimSpace(header) == "*" {
+		return true
+	}
+
+	if !weak && strings.HasPrefix(etag, "W/") {
+		return false
+	}
+
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if !weak && strings.HasPrefix(candidate, "W/") {
+			continue
+		}
+
+		if strings.TrimPrefix(candidate, "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+
+	return false
+}
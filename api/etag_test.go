@@ -0,0 +1,192 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestETags(t *testing.T) {
+	book := myStruct{Name: "Things fall apart"}
+
+	router := chi.NewRouter()
+	router.Use(Recoverer("production"))
+	router.With(WithETags(StrongETag)).Get("/strong", func(w http.ResponseWriter, r *http.Request) {
+		Success(r, w, book)
+	})
+	router.With(WithETags(WeakETag)).Get("/weak", func(w http.ResponseWriter, r *http.Request) {
+		Success(r, w, book)
+	})
+	router.Get("/custom", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("ETag", `"v5"`)
+		Success(r, w, book)
+	})
+	router.Get("/none", func(w http.ResponseWriter, r *http.Request) {
+		Success(r, w, book)
+	})
+	router.Put("/strong", func(w http.ResponseWriter, r *http.Request) {
+		IfMatch(r, ETag(book, StrongETag))
+		Success(r, w, book)
+	})
+
+	send := func(method, path string, headers map[string]string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest(method, path, strings.NewReader(`{"name": "Arrow of God"}`))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		router.ServeHTTP(res, req)
+
+		return res
+	}
+
+	t.Run("computes ETags from the body", func(t *testing.T) {
+		strong := send("GET", "/strong", nil).Header().Get("ETag")
+		if strong != ETag(book, StrongETag) {
+			t.Errorf("Expected the ETag to be %s, got %s", ETag(book, StrongETag), strong)
+		}
+
+		weak := send("GET", "/weak", nil).Header().Get("ETag")
+		if weak != "W/"+strong {
+			t.Errorf("Expected the ETag to be %s, got %s", "W/"+strong, weak)
+		}
+
+		if etag := send("GET", "/none", nil).Header().Get("ETag"); etag != "" {
+			t.Errorf("Expected no ETag, got %s", etag)
+		}
+	})
+
+	t.Run("responds with 304 when If-None-Match matches", func(t *testing.T) {
+		for _, path := range []string{"/strong", "/weak", "/custom"} {
+			etag := send("GET", path, nil).Header().Get("ETag")
+
+			res := send("GET", path, map[string]string{"If-None-Match": `"stale", ` + etag})
+			if res.Code != http.StatusNotModified {
+				t.Errorf("Expected the status code for %s to be %d, got %d", path, http.StatusNotModified, res.Code)
+			}
+
+			if res.Body.Len() != 0 {
+				t.Errorf("Expected the body for %s to be empty, got %s", path, res.Body.String())
+			}
+		}
+	})
+
+	t.Run("responds with 200 when If-None-Match is stale", func(t *testing.T) {
+		res := send("GET", "/strong", map[string]string{"If-None-Match": `"stale"`})
+		if res.Code != http.StatusOK {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusOK, res.Code)
+		}
+	})
+
+	t.Run("responds with 412 when If-Match fails", func(t *testing.T) {
+		res := send("PUT", "/strong", map[string]string{"If-Match": `"stale"`})
+		if res.Code != http.StatusPreconditionFailed {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusPreconditionFailed, res.Code)
+		}
+
+		res = send("PUT", "/strong", map[string]string{"If-Match": "W/" + ETag(book, StrongETag)})
+		if res.Code != http.StatusPreconditionFailed {
+			t.Errorf("Expected weak ETags to fail If-Match, got %d", res.Code)
+		}
+	})
+
+	t.Run("allows updates when If-Match matches", func(t *testing.T) {
+		res := send("PUT", "/strong", map[string]string{"If-Match": ETag(book, StrongETag)})
+		if res.Code != http.StatusOK {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusOK, res.Code)
+		}
+
+		res = send("PUT", "/strong", nil)
+		if res.Code != http.StatusOK {
+			t.Errorf("Expected the status code without If-Match to be %d, got %d", http.StatusOK, res.Code)
+		}
+	})
+}
+
+func TestLastModified(t *testing.T) {
+	book := myStruct{Name: "Things fall apart"}
+	modified := time.Date(2024, time.March, 1, 12, 30, 0, 0, time.UTC)
+
+	router := chi.NewRouter()
+	router.Use(Recoverer("production"))
+	router.With(WithETags(StrongETag)).Get("/books", func(w http.ResponseWriter, r *http.Request) {
+		LastModified(w, modified)
+		Success(r, w, book)
+	})
+	router.Put("/books", func(w http.ResponseWriter, r *http.Request) {
+		IfUnmodifiedSince(r, modified)
+		Success(r, w, book)
+	})
+
+	send := func(method string, headers map[string]string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest(method, "/books", strings.NewReader(`{"name": "Arrow of God"}`))
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		router.ServeHTTP(res, req)
+
+		return res
+	}
+
+	before := modified.Add(-time.Hour).Format(http.TimeFormat)
+	after := modified.Add(time.Hour).Format(http.TimeFormat)
+
+	t.Run("sets the Last-Modified header", func(t *testing.T) {
+		res := send("GET", nil)
+		if lm := res.Header().Get("Last-Modified"); lm != modified.Format(http.TimeFormat) {
+			t.Errorf("Expected Last-Modified to be %s, got %s", modified.Format(http.TimeFormat), lm)
+		}
+	})
+
+	t.Run("responds with 304 when If-Modified-Since is fresh", func(t *testing.T) {
+		res := send("GET", map[string]string{"If-Modified-Since": after})
+		if res.Code != http.StatusNotModified {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusNotModified, res.Code)
+		}
+
+		res = send("GET", map[string]string{"If-Modified-Since": modified.Format(http.TimeFormat)})
+		if res.Code != http.StatusNotModified {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusNotModified, res.Code)
+		}
+	})
+
+	t.Run("responds with 200 when If-Modified-Since is stale", func(t *testing.T) {
+		res := send("GET", map[string]string{"If-Modified-Since": before})
+		if res.Code != http.StatusOK {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusOK, res.Code)
+		}
+	})
+
+	t.Run("prefers If-None-Match over If-Modified-Since", func(t *testing.T) {
+		res := send("GET", map[string]string{"If-None-Match": `"stale"`, "If-Modified-Since": after})
+		if res.Code != http.StatusOK {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusOK, res.Code)
+		}
+	})
+
+	t.Run("responds with 412 when If-Unmodified-Since fails", func(t *testing.T) {
+		res := send("PUT", map[string]string{"If-Unmodified-Since": before})
+		if res.Code != http.StatusPreconditionFailed {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusPreconditionFailed, res.Code)
+		}
+	})
+
+	t.Run("allows updates when If-Unmodified-Since holds", func(t *testing.T) {
+		for _, headers := range []map[string]string{
+			{"If-Unmodified-Since": after},
+			{"If-Unmodified-Since": modified.Format(http.TimeFormat)},
+			{"If-Unmodified-Since": before, "If-Match": "*"},
+			nil,
+		} {
+			res := send("PUT", headers)
+			if res.Code != http.StatusOK {
+				t.Errorf("Expected the status code with %v to be %d, got %d", headers, http.StatusOK, res.Code)
+			}
+		}
+	})
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"errors"
+	"net/http"
+)
+
+// HandlerFunc is an HTTP handler that reports failure by returning an error
+// rather than panicking.
+type HandlerFunc func(w http.ResponseWriter, r *http.Request) error
+
+// ServeHTTP calls h and responds to any error it returns the same way Recoverer
+// would, i.e. Err(wrapped or not) is sent using Error and every other error is
+// logged and responds with a 500(or 504 if the request context timed out).
+func (h HandlerFunc) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	err := h(w, r)
+	if err == nil {
+		return
+	}
+
+	var e Err
+	if errors.As(err, &e) {
+		Error(r, w, e)
+	} else {
+		unknownError(r, w, err)
+	}
+}
+
+// Handle adapts h for use with chi's routing methods.
+//
+//	router.Get("/books/{id}", api.Handle(func(w http.ResponseWriter, r *http.Request) error {
+//		id, err := api.TryIDParam(r, "id")
+//		if err != nil {
+//			return err
+//		}
+//		...
+//	}))
+func Handle(h HandlerFunc) http.HandlerFunc {
+	return h.ServeHTTP
+}
@@ -0,0 +1,85 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestHandle(t *testing.T) {
+	router := chi.NewRouter()
+
+	router.Get("/entities/{id}", Handle(func(w http.ResponseWriter, r *http.Request) error {
+		id, err := TryIDParam(r, "id")
+		if err != nil {
+			return err
+		}
+
+		_, _ = w.Write([]byte(fmt.Sprint(id)))
+		return nil
+	}))
+
+	router.Post("/entities", Handle(func(w http.ResponseWriter, r *http.Request) error {
+		var m myStruct
+		if err := TryReadJSON(r, &m); err != nil {
+			return fmt.Errorf("could not create entity: %w", err)
+		}
+
+		Success(r, w, m)
+		return nil
+	}))
+
+	router.Get("/failure", Handle(func(_ http.ResponseWriter, _ *http.Request) error {
+		return errors.New("failure")
+	}))
+
+	t.Run("responds normally without errors", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/entities/24", nil)
+		router.ServeHTTP(res, req)
+
+		if res.Code != http.StatusOK {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusOK, res.Code)
+		}
+
+		if res.Body.String() != "24" {
+			t.Errorf("Expected the body to be 24, got %s", res.Body.String())
+		}
+	})
+
+	t.Run("responds with returned Err", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/entities/yuko", nil)
+		router.ServeHTTP(res, req)
+
+		if res.Code != http.StatusBadRequest {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusBadRequest, res.Code)
+		}
+	})
+
+	t.Run("responds with wrapped Err", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/entities", strings.NewReader(`{}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(res, req)
+
+		if res.Code != http.StatusBadRequest {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusBadRequest, res.Code)
+		}
+	})
+
+	t.Run("responds with 500 for other errors", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/failure", nil)
+		router.ServeHTTP(res, req)
+
+		if res.Code != http.StatusInternalServerError {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusInternalServerError, res.Code)
+		}
+	})
+}
@@ -0,0 +1,177 @@
+package api
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/noxecane/anansi/json"
+	"github.com/noxecane/anansi/requests"
+	"github.com/noxecane/anansi/responses"
+	"github.com/noxecane/anansi/tokens"
+	"github.com/rs/zerolog"
+)
+
+// IdempotencyHeader is the header clients use to pass their idempotency keys.
+// Change it if you use something different
+var IdempotencyHeader = "Idempotency-Key"
+
+// ReplayedHeader is set on responses that were replayed for a retried request.
+var ReplayedHeader = "Idempotent-Replayed"
+
+// IdempotencyScope identifies the caller of a request, so idempotency keys from different
+// callers never share responses. It defaults to the Authorization header, falling back
+// to the client's IP address. Apps using cookie sessions should identify callers by
+// their session instead.
+var IdempotencyScope = func(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); auth != "" {
+		return "auth:" + auth
+	}
+
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return "ip:" + r.RemoteAddr
+	}
+
+	return "ip:" + host
+}
+
+type idempotentResponse struct {
+	Hash    string      `json:"hash"`
+	Pending bool        `json:"pending"`
+	Code    int         `json:"code"`
+	Headers http.Header `json:"headers"`
+	Body    []byte      `json:"body"`
+}
+
+// Idempotent creates a middleware that makes POST and PATCH requests with an idempotency
+// key safe to retry. The first request with a key gets processed while the key is locked
+// in the store, and its response is kept for the duration d. Retries get that response
+// replayed instead. It responds with a 409 for retries made while the first request is
+// still running, and a 422 if the request body differs from the first one.
+//
+// Responses with a 5xx status and handlers that panic release the key so the request
+// can be retried. Keys are scoped to the request path and the caller(see IdempotencyScope).
+func Idempotent(store tokens.IdempotencyStore, d time.Duration) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			idemKey := r.Header.Get(IdempotencyHeader)
+			if idemKey == "" || (r.Method != http.MethodPost && r.Method != http.MethodPatch) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			ctx := r.Context()
+
+			// keep credentials out of the store
+			scope := sha256.Sum256([]byte(IdempotencyScope(r)))
+			key := r.Method + ":" + r.URL.Path + ":" + hex.EncodeToString(scope[:]) + ":" + idemKey
+
+			requests.LimitBody(r)
+			body, err := io.ReadAll(r.Body)
+			if err != nil {
+				panic(bodyErr(err))
+			}
+			r.Body = io.NopCloser(bytes.NewReader(body))
+
+			sum := sha256.Sum256(body)
+			hash := hex.EncodeToString(sum[:])
+
+			pending, _ := json.Marshal(idempotentResponse{Hash: hash, Pending: true})
+			ok, raw, err := store.Reserve(ctx, key, pending, d)
+			if err != nil {
+				panic(err)
+			}
+
+			if !ok {
+				var saved idempotentResponse
+				if err := json.Unmarshal(raw, &saved); err != nil {
+					panic(err)
+				}
+
+				replay(r, w, saved, hash)
+				return
+			}
+
+			rw := responses.Record(w)
+			completed := false
+
+			defer func() {
+				if completed && rw.Code() < http.StatusInternalServerError {
+					return
+				}
+
+				// let the client retry requests that failed
+				if err := store.Release(ctx, key); err != nil {
+					zerolog.Ctx(ctx).Err(err).Msg("failed to release idempotency key")
+				}
+			}()
+
+			next.ServeHTTP(rw, r)
+			completed = true
+
+			if rw.Code() >= http.StatusInternalServerError {
+				return
+			}
+
+			raw, _ = json.Marshal(idempotentResponse{
+				Hash:    hash,
+				Code:    rw.Code(),
+				Headers: rw.Headers(),
+				Body:    rw.Body(),
+			})
+			if err := store.Save(ctx, key, raw, d); err != nil {
+				zerolog.Ctx(ctx).Err(err).Msg("failed to save idempotent response")
+			}
+		})
+	}
+}
+
+func replay(r *http.Request, w http.ResponseWriter, saved idempotentResponse, hash string) {
+	if saved.Hash != hash {
+		panic(Err{
+			Code:    http.StatusUnprocessableEntity,
+			Message: "This idempotency key has already been used for a different request.",
+		})
+	}
+
+	if saved.Pending {
+		panic(Err{
+			Code:    http.StatusConflict,
+			Message: "A request with this idempotency key is still being processed.",
+		})
+	}
+
+	for k, v := range saved.Headers {
+		w.Header()[k] = v
+	}
+	w.Header().Set(ReplayedHeader, "true")
+
+	w.WriteHeader(saved.Code)
+	// responses like 204 and 304 can't have a body
+	if len(saved.Body) > 0 {
+		if _, err := w.Write(saved.Body); err != nil {
+			panic(err)
+		}
+	}
+
+	log := zerolog.Ctx(r.Context())
+	if responses.AccessLogged(r) {
+		log.UpdateContext(func(ctx zerolog.Context) zerolog.Context {
+			return ctx.Bool("replayed", true)
+		})
+		return
+	}
+
+	if requests.SkipLog(r, saved.Code) {
+		return
+	}
+
+	log.Info().
+		Int("status", saved.Code).
+		Msg("replayed idempotent response")
+}
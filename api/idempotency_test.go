@@ -0,0 +1,256 @@
+package api
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/noxecane/anansi/requests"
+	"github.com/noxecane/anansi/responses"
+	"github.com/noxecane/anansi/tokens"
+	"github.com/rs/zerolog"
+	"github.com/segmentio/ksuid"
+)
+
+func TestIdempotent(t *testing.T) {
+	var calls int32
+	block := make(chan struct{})
+
+	router := chi.NewRouter()
+	router.Use(Recoverer("production"))
+	router.Use(Idempotent(tokens.NewMemoryIdempotencyStore(), time.Minute))
+
+	router.Post("/payments", func(w http.ResponseWriter, r *http.Request) {
+		var m myStruct
+		ReadJSON(r, &m)
+
+		n := atomic.AddInt32(&calls, 1)
+		w.Header().Set("X-Call", strconv.Itoa(int(n)))
+		Send(r, w, http.StatusCreated, m)
+	})
+	router.Post("/slow", func(w http.ResponseWriter, r *http.Request) {
+		<-block
+		Success(r, w, nil)
+	})
+	router.Post("/empty", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	})
+	router.Post("/fails", func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		panic(errBookNotFound)
+	})
+
+	sendAs := func(auth, path, key, body string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", path, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if auth != "" {
+			req.Header.Set("Authorization", auth)
+		}
+		if key != "" {
+			req.Header.Set(IdempotencyHeader, key)
+		}
+		router.ServeHTTP(res, req)
+
+		return res
+	}
+
+	send := func(path, key, body string) *httptest.ResponseRecorder {
+		return sendAs("", path, key, body)
+	}
+
+	t.Run("replays empty responses", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		key := ksuid.New().String()
+
+		first := send("/empty", key, `{}`)
+		second := send("/empty", key, `{}`)
+
+		if calls != 1 {
+			t.Errorf("Expected the handler to be called once, got %d", calls)
+		}
+
+		if first.Code != http.StatusOK || second.Code != http.StatusOK {
+			t.Errorf("Expected both status codes to be %d, got %d and %d", http.StatusOK, first.Code, second.Code)
+		}
+	})
+
+	t.Run("replays responses without a body", func(t *testing.T) {
+		// without Recoverer so failed writes aren't hidden
+		bare := chi.NewRouter()
+		bare.Use(Idempotent(tokens.NewMemoryIdempotencyStore(), time.Minute))
+		bare.Post("/nothing", func(w http.ResponseWriter, r *http.Request) {
+			NoContent(r, w)
+		})
+
+		key := ksuid.New().String()
+		for range 2 {
+			res := httptest.NewRecorder()
+			req := httptest.NewRequest("POST", "/nothing", strings.NewReader(`{}`))
+			req.Header.Set(IdempotencyHeader, key)
+			bare.ServeHTTP(res, req)
+
+			if res.Code != http.StatusNoContent {
+				t.Errorf("Expected the status code to be %d, got %d", http.StatusNoContent, res.Code)
+			}
+		}
+	})
+
+	t.Run("scopes keys to the caller", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		key := ksuid.New().String()
+
+		first := sendAs("Bearer first", "/payments", key, `{"name": "Arrow of God"}`)
+		second := sendAs("Bearer second", "/payments", key, `{"name": "Arrow of God"}`)
+
+		if calls != 2 {
+			t.Errorf("Expected the handler to be called twice, got %d", calls)
+		}
+
+		if second.Header().Get(ReplayedHeader) != "" || second.Header().Get("X-Call") == first.Header().Get("X-Call") {
+			t.Error("Expected the first caller's response not to be replayed")
+		}
+	})
+
+	t.Run("replays the response for retries", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		key := ksuid.New().String()
+
+		first := send("/payments", key, `{"name": "Arrow of God"}`)
+		second := send("/payments", key, `{"name": "Arrow of God"}`)
+
+		if calls != 1 {
+			t.Errorf("Expected the handler to be called once, got %d", calls)
+		}
+
+		if second.Code != first.Code {
+			t.Errorf("Expected the status code to be %d, got %d", first.Code, second.Code)
+		}
+
+		if second.Body.String() != first.Body.String() {
+			t.Errorf("Expected the body to be %s, got %s", first.Body.String(), second.Body.String())
+		}
+
+		if second.Header().Get("X-Call") != "1" {
+			t.Errorf("Expected the X-Call header to be replayed, got %s", second.Header().Get("X-Call"))
+		}
+
+		if second.Header().Get(ReplayedHeader) != "true" {
+			t.Errorf("Expected the %s header to be set", ReplayedHeader)
+		}
+	})
+
+	t.Run("ignores requests without a key", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+
+		send("/payments", "", `{"name": "Arrow of God"}`)
+		send("/payments", "", `{"name": "Arrow of God"}`)
+
+		if calls != 2 {
+			t.Errorf("Expected the handler to be called twice, got %d", calls)
+		}
+	})
+
+	t.Run("rejects retries with a different body", func(t *testing.T) {
+		key := ksuid.New().String()
+
+		send("/payments", key, `{"name": "Arrow of God"}`)
+		res := send("/payments", key, `{"name": "No longer at ease"}`)
+
+		if res.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusUnprocessableEntity, res.Code)
+		}
+	})
+
+	t.Run("rejects retries while the request is running", func(t *testing.T) {
+		key := ksuid.New().String()
+		done := make(chan struct{})
+
+		go func() {
+			send("/slow", key, "")
+			close(done)
+		}()
+
+		// wait for the first request to lock the key
+		time.Sleep(50 * time.Millisecond)
+
+		res := send("/slow", key, "")
+		close(block)
+		<-done
+
+		if res.Code != http.StatusConflict {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusConflict, res.Code)
+		}
+	})
+
+	t.Run("releases the key when the handler fails", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+		key := ksuid.New().String()
+
+		send("/fails", key, "")
+		send("/fails", key, "")
+
+		if calls != 2 {
+			t.Errorf("Expected the handler to be called twice, got %d", calls)
+		}
+	})
+	t.Run("rejects bodies over the decoding limit", func(t *testing.T) {
+		atomic.StoreInt32(&calls, 0)
+
+		limited := chi.NewRouter()
+		limited.Use(Recoverer("production"))
+		limited.Use(requests.Decoding(requests.DecodeOptions{MaxBytes: 16}))
+		limited.Use(Idempotent(tokens.NewMemoryIdempotencyStore(), time.Minute))
+		limited.Post("/payments", func(w http.ResponseWriter, r *http.Request) {
+			atomic.AddInt32(&calls, 1)
+		})
+
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/payments", strings.NewReader(`{"name": "Things fall apart"}`))
+		req.Header.Set(IdempotencyHeader, ksuid.New().String())
+		limited.ServeHTTP(res, req)
+
+		if res.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusRequestEntityTooLarge, res.Code)
+		}
+
+		if calls != 0 {
+			t.Errorf("Expected the handler not to be called, got %d calls", calls)
+		}
+	})
+	t.Run("logs replays once with the access log", func(t *testing.T) {
+		logOut := &bytes.Buffer{}
+
+		logged := chi.NewRouter()
+		logged.Use(requests.AttachLogger(zerolog.New(logOut)))
+		logged.Use(responses.AccessLog(nil))
+		logged.Use(Recoverer("production"))
+		logged.Use(Idempotent(tokens.NewMemoryIdempotencyStore(), time.Minute))
+		logged.Post("/payments", func(w http.ResponseWriter, r *http.Request) {
+			NoContent(r, w)
+		})
+
+		key := ksuid.New().String()
+		for range 2 {
+			logOut.Reset()
+
+			req := httptest.NewRequest("POST", "/payments", strings.NewReader(`{}`))
+			req.Header.Set(IdempotencyHeader, key)
+			logged.ServeHTTP(httptest.NewRecorder(), req)
+		}
+
+		if n := strings.Count(logOut.String(), "\n"); n != 1 {
+			t.Errorf("Expected one log line for the replay, got %d", n)
+		}
+
+		if !strings.Contains(logOut.String(), `"replayed":true`) {
+			t.Errorf("Expected the replay to be recorded on the access line, got %s", logOut.String())
+		}
+	})
+}
@@ -7,6 +7,7 @@ import (
 	"os"
 	"runtime"
 
+	"github.com/noxecane/anansi/responses"
 	"github.com/noxecane/anansi/sessions"
 	"github.com/rs/zerolog"
 )
@@ -18,21 +19,20 @@ var STACK_SIZE = 12 * 1024
 // printing(optionally stack trace in dev env) and responding to the client for all
 // errors except Err.
 //
-// Note that all errors(bar Err and request context timeouts) respond with a 500
+// Note that all errors(bar Err and request context timeouts) respond with a 500.
+// http.ErrAbortHandler is panicked again so net/http can abort the response.
 func Recoverer(env string) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		fn := func(w http.ResponseWriter, r *http.Request) {
 			defer func() {
-				if rvr := recover(); rvr != nil && rvr != http.ErrAbortHandler {
+				if rvr := recover(); rvr != nil {
+					if rvr == http.ErrAbortHandler {
+						panic(rvr)
+					}
+
 					if e, ok := rvr.(Err); ok {
 						Error(r, w, e)
 					} else {
-						ctx := r.Context()
-						// always log errors regardless of the type
-						log := zerolog.Ctx(ctx)
-						err := rvr.(error) // it would be serious if this wasn't an error
-						log.Err(err).Msg("")
-
 						// give dev a chance to trace unknown errors
 						if env == "dev" || env == "test" {
 							stack := make([]byte, STACK_SIZE)
@@ -40,12 +40,7 @@ func Recoverer(env string) func(http.Handler) http.Handler {
 							fmt.Fprintf(os.Stderr, "recovering from panic:\n%s", stack)
 						}
 
-						// make sure timeouts are reported as 504
-						if ctx.Err() == context.DeadlineExceeded {
-							http.Error(w, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
-						} else {
-							http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
-						}
+						unknownError(r, w, rvr.(error)) // it would be serious if this wasn't an error
 					}
 				}
 			}()
@@ -57,6 +52,24 @@ func Recoverer(env string) func(http.Handler) http.Handler {
 	}
 }
 
+// unknownError logs errors that are not Err and responds with a 500, or a 504
+// if the request context timed out.
+func unknownError(r *http.Request, w http.ResponseWriter, err error) {
+	ctx := r.Context()
+	// always log errors regardless of the type
+	if !responses.LogError(r, err) {
+		log := zerolog.Ctx(ctx)
+		log.Err(err).Msg("")
+	}
+
+	// make sure timeouts are reported as 504
+	if ctx.Err() == context.DeadlineExceeded {
+		http.Error(w, http.StatusText(http.StatusGatewayTimeout), http.StatusGatewayTimeout)
+	} else {
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+	}
+}
+
 func Headless(manager *sessions.Manager) func(http.Handler) http.Handler {
 	return func(next http.Handler) http.Handler {
 		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
@@ -0,0 +1,145 @@
+package api
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/noxecane/anansi/requests"
+)
+
+// Page is the envelope for a page of a list. Next and Prev are links to the
+// adjacent pages, empty when there's no such page.
+type Page[T any] struct {
+	Items []T    `json:"items"`
+	Next  string `json:"next,omitempty"`
+	Prev  string `json:"prev,omitempty"`
+}
+
+// PageParams reads offset/limit pagination from the query of the request. It panics
+// with a 400 if the offset is negative or the limit is not between 1 and max.
+func PageParams(r *http.Request, max int) requests.Page {
+	p, err := TryPageParams(r, max)
+	if err != nil {
+		panic(err)
+	}
+
+	return p
+}
+
+// TryPageParams is PageParams that returns the Err rather than panic.
+func TryPageParams(r *http.Request, max int) (requests.Page, error) {
+	p, err := requests.PageParams(r, max)
+	if err != nil {
+		return p, queryErr(err)
+	}
+
+	return p, nil
+}
+
+// CursorParams reads the limit and sealed cursor(decoded into v) from the query of
+// the request. It panics with a 400 if the limit is not between 1 and max or the
+// cursor has been tampered with.
+func CursorParams(r *http.Request, secret []byte, max int, v any) int {
+	limit, err := TryCursorParams(r, secret, max, v)
+	if err != nil {
+		panic(err)
+	}
+
+	return limit
+}
+
+// TryCursorParams is CursorParams that returns the Err rather than panic.
+func TryCursorParams(r *http.Request, secret []byte, max int, v any) (int, error) {
+	limit, err := requests.CursorParams(r, secret, max, v)
+	if err != nil {
+		return 0, queryErr(err)
+	}
+
+	return limit, nil
+}
+
+// NewPage creates the page for items loaded using offset/limit pagination. It
+// assumes there's a next page when the page is full.
+func NewPage[T any](r *http.Request, p requests.Page, items []T) Page[T] {
+	page := Page[T]{Items: items}
+
+	if len(items) >= p.Limit {
+		page.Next = pageURL(r, map[string]string{
+			"offset": strconv.Itoa(p.Offset + p.Limit),
+			"limit":  strconv.Itoa(p.Limit),
+		})
+	}
+
+	if p.Offset > 0 {
+		prev := p.Offset - p.Limit
+		if prev < 0 {
+			prev = 0
+		}
+
+		page.Prev = pageURL(r, map[string]string{
+			"offset": strconv.Itoa(prev),
+			"limit":  strconv.Itoa(p.Limit),
+		})
+	}
+
+	return page
+}
+
+// NewCursorPage creates the page for items loaded using cursor pagination. next and
+// prev are sealed into the cursors of the links to the adjacent pages; pass nil
+// when there's no such page.
+func NewCursorPage[T any](r *http.Request, secret []byte, items []T, next, prev any) (Page[T], error) {
+	var err error
+	page := Page[T]{Items: items}
+
+	if next != nil {
+		if page.Next, err = cursorURL(r, secret, next); err != nil {
+			return page, err
+		}
+	}
+
+	if prev != nil {
+		if page.Prev, err = cursorURL(r, secret, prev); err != nil {
+			return page, err
+		}
+	}
+
+	return page, nil
+}
+
+// Paginate sends the page using Success after adding the links to the adjacent pages
+// to the Link header(RFC 8288).
+func Paginate[T any](r *http.Request, w http.ResponseWriter, page Page[T]) {
+	if page.Next != "" {
+		w.Header().Add("Link", "<"+page.Next+`>; rel="next"`)
+	}
+
+	if page.Prev != "" {
+		w.Header().Add("Link", "<"+page.Prev+`>; rel="prev"`)
+	}
+
+	Success(r, w, page)
+}
+
+// pageURL creates a link to the request's URL with some of the query params replaced
+func pageURL(r *http.Request, params map[string]string) string {
+	u := *r.URL
+	query := u.Query()
+
+	for k, v := range params {
+		query.Set(k, v)
+	}
+	u.RawQuery = query.Encode()
+
+	return u.RequestURI()
+}
+
+// cursorURL creates a link to the request's URL with pos sealed as the cursor
+func cursorURL(r *http.Request, secret []byte, pos any) (string, error) {
+	cursor, err := requests.EncodeCursor(secret, pos)
+	if err != nil {
+		return "", err
+	}
+
+	return pageURL(r, map[string]string{"cursor": cursor}), nil
+}
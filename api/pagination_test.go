@@ -0,0 +1,101 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/noxecane/anansi/json"
+	"github.com/noxecane/anansi/requests"
+)
+
+func TestPaginate(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(Recoverer("production"))
+	router.Get("/books", func(w http.ResponseWriter, r *http.Request) {
+		p := PageParams(r, 10)
+		books := make([]int, 0, p.Limit)
+		for i := p.Offset; i < p.Offset+p.Limit && i < 25; i++ {
+			books = append(books, i)
+		}
+
+		Paginate(r, w, NewPage(r, p, books))
+	})
+
+	get := func(target string) (*httptest.ResponseRecorder, Page[int]) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", target, nil)
+		router.ServeHTTP(res, req)
+
+		var page Page[int]
+		_ = json.Unmarshal(res.Body.Bytes(), &page)
+
+		return res, page
+	}
+
+	t.Run("links to the adjacent pages", func(t *testing.T) {
+		res, page := get("/books?offset=10&limit=5&genre=fiction")
+
+		if len(page.Items) != 5 || page.Items[0] != 10 {
+			t.Errorf("Expected items 10 to 14, got %v", page.Items)
+		}
+
+		next, _ := url.Parse(page.Next)
+		if next.Query().Get("offset") != "15" || next.Query().Get("genre") != "fiction" {
+			t.Errorf("Expected next page to keep the query and start at 15, got %s", page.Next)
+		}
+
+		prev, _ := url.Parse(page.Prev)
+		if prev.Query().Get("offset") != "5" {
+			t.Errorf("Expected previous page to start at 5, got %s", page.Prev)
+		}
+
+		if links := res.Header().Values("Link"); len(links) != 2 {
+			t.Errorf("Expected next and prev Link headers, got %v", links)
+		}
+	})
+
+	t.Run("stops at the last page", func(t *testing.T) {
+		res, page := get("/books?offset=20&limit=10")
+
+		if page.Next != "" {
+			t.Errorf("Expected no next page, got %s", page.Next)
+		}
+
+		if links := res.Header().Values("Link"); len(links) != 1 {
+			t.Errorf("Expected only the prev Link header, got %v", links)
+		}
+	})
+
+	t.Run("rejects limits above the max", func(t *testing.T) {
+		if res, _ := get("/books?limit=50"); res.Code != http.StatusBadRequest {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusBadRequest, res.Code)
+		}
+	})
+}
+
+func TestNewCursorPage(t *testing.T) {
+	req := httptest.NewRequest("GET", "/books?limit=5", nil)
+
+	page, err := NewCursorPage(req, []byte(secret), []int{1, 2}, map[string]int{"id": 2}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if page.Prev != "" {
+		t.Errorf("Expected no previous page, got %s", page.Prev)
+	}
+
+	next, _ := url.Parse(page.Next)
+
+	var pos map[string]int
+	if err := requests.DecodeCursor([]byte(secret), next.Query().Get("cursor"), &pos); err != nil {
+		t.Fatal(err)
+	}
+
+	if pos["id"] != 2 {
+		t.Errorf("Expected the next cursor to be at 2, got %v", pos)
+	}
+}
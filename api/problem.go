@@ -0,0 +1,165 @@
+package api
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/noxecane/anansi/json"
+)
+
+// ProblemContentType is the content type of RFC 7807 problem details
+const ProblemContentType = "application/problem+json"
+
+// ProblemDetails makes Error respond with problem details for every router. Use
+// the Problems middleware to do so for a single router instead.
+var ProblemDetails = false
+
+type problemsKey struct{}
+
+// Problem is the RFC 7807 representation of an Err.
+type Problem struct {
+	Type     string
+	Title    string
+	Status   int
+	Detail   string
+	Instance string
+	// Extensions are members of the problem outside those defined by the RFC
+	Extensions map[string]interface{}
+}
+
+// Problems is a middleware that makes Error respond with problem details for
+// the routes it's used on.
+func Problems(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := context.WithValue(r.Context(), problemsKey{}, true)
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// NewProblem converts the Err to problem details, using the ID of the request as
+// the instance.
+func NewProblem(r *http.Request, err Err) Problem {
+	p := Problem{
+		Type:       err.Type,
+		Title:      http.StatusText(err.Code),
+		Status:     err.Code,
+		Detail:     err.Message,
+		Instance:   middleware.GetReqID(r.Context()),
+		Extensions: make(map[string]interface{}),
+	}
+
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+
+	for k, v := range err.Extensions {
+		p.Extensions[k] = v
+	}
+
+	if err.Data != nil {
+		p.Extensions["data"] = err.Data
+	}
+
+	if err.Kind != "" {
+		p.Extensions["code"] = err.Kind
+	}
+
+	return p
+}
+
+// Err converts the problem details back to an Err
+func (p Problem) Err() Err {
+	e := Err{
+		Code:    p.Status,
+		Message: p.Detail,
+		Type:    p.Type,
+	}
+
+	if e.Message == "" {
+		e.Message = p.Title
+	}
+
+	if e.Type == "about:blank" {
+		e.Type = ""
+	}
+
+	for k, v := range p.Extensions {
+		switch k {
+		case "data":
+			e.Data = v
+		case "code":
+			e.Kind, _ = v.(string)
+		default:
+			if e.Extensions == nil {
+				e.Extensions = make(map[string]interface{})
+			}
+			e.Extensions[k] = v
+		}
+	}
+
+	return e
+}
+
+func (p Problem) MarshalJSON() ([]byte, error) {
+	members := make(map[string]interface{}, len(p.Extensions)+5)
+	for k, v := range p.Extensions {
+		members[k] = v
+	}
+
+	members["type"] = p.Type
+	members["title"] = p.Title
+	members["status"] = p.Status
+
+	if p.Detail != "" {
+		members["detail"] = p.Detail
+	}
+
+	if p.Instance != "" {
+		members["instance"] = p.Instance
+	}
+
+	return json.Marshal(members)
+}
+
+func (p *Problem) UnmarshalJSON(b []byte) error {
+	var members map[string]interface{}
+	if err := json.Unmarshal(b, &members); err != nil {
+		return err
+	}
+
+	*p = Problem{}
+
+	for k, v := range members {
+		switch k {
+		case "type":
+			p.Type, _ = v.(string)
+		case "title":
+			p.Title, _ = v.(string)
+		case "status":
+			if status, ok := v.(float64); ok {
+				p.Status = int(status)
+			}
+		case "detail":
+			p.Detail, _ = v.(string)
+		case "instance":
+			p.Instance, _ = v.(string)
+		default:
+			if p.Extensions == nil {
+				p.Extensions = make(map[string]interface{})
+			}
+			p.Extensions[k] = v
+		}
+	}
+
+	return nil
+}
+
+func useProblems(r *http.Request) bool {
+	if ProblemDetails {
+		return true
+	}
+
+	enabled, _ := r.Context().Value(problemsKey{}).(bool)
+	return enabled
+}
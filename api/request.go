@@ -10,34 +10,91 @@ import (
 )
 
 // ReadJSON parses the body of an http request into the value pointed by v.
-// It panics with a 415 error if the content type is not JSON, a 400 if the value fails
-// ozzo validation or any other error(JSON decode error for instance)
+// It panics with a 415 error if the content type is not JSON, a 413 if the body is larger
+// than the limit set with requests.Decoding, a 400 if the value fails ozzo validation or
+// any other error(JSON decode error for instance)
 func ReadJSON(r *http.Request, v interface{}) {
+	if err := TryReadJSON(r, v); err != nil {
+		panic(err)
+	}
+}
+
+// TryReadJSON is ReadJSON that returns the Err rather than panic.
+func TryReadJSON(r *http.Request, v interface{}) error {
 	err := requests.ReadJSON(r, v)
 	if err == nil {
-		return
+		return nil
+	}
+
+	return bodyErr(err)
+}
+
+// Bind fills the struct v from the JSON body, URL params, headers and query of the
+// request(see requests.Bind). It panics with a 400 if a value can't be converted to
+// the type of its field or v fails ozzo validation, and the same errors as ReadJSON
+// for the body.
+func Bind(r *http.Request, v interface{}) {
+	if err := TryBind(r, v); err != nil {
+		panic(err)
+	}
+}
+
+// TryBind is Bind that returns the Err rather than panic.
+func TryBind(r *http.Request, v interface{}) error {
+	err := requests.Bind(r, v)
+	if err == nil {
+		return nil
 	}
 
+	var bindErr *requests.BindError
+	if errors.As(err, &bindErr) {
+		return conversionErr(bindErr)
+	}
+
+	return bodyErr(err)
+}
+
+// conversionErr reports the value that couldn't be converted like a validation error
+func conversionErr(err *requests.BindError) Err {
+	return Err{
+		Code:    http.StatusBadRequest,
+		Message: "We could not validate your request.",
+		Data: validation.Errors{
+			err.Name: fmt.Errorf("must be a valid %s", err.Type),
+		},
+		Err: err,
+	}
+}
+
+// bodyErr converts errors from reading request bodies to an Err
+func bodyErr(err error) error {
 	var e validation.Errors
+	var tooLarge *http.MaxBytesError
 	switch {
 	case err == requests.ErrNotJSON:
-		panic(Err{
+		return Err{
 			Code:    http.StatusUnsupportedMediaType,
 			Message: http.StatusText(http.StatusUnsupportedMediaType),
 			Err:     err,
-		})
+		}
+	case errors.As(err, &tooLarge):
+		return Err{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("Your request body cannot be larger than %d bytes.", tooLarge.Limit),
+			Err:     err,
+		}
 	case errors.As(err, &e):
-		panic(Err{
+		return Err{
 			Code:    http.StatusBadRequest,
 			Message: "We could not validate your request.",
 			Data:    e,
-		})
+		}
 	default:
-		panic(Err{
+		return Err{
 			Code:    http.StatusBadRequest,
 			Message: "We cannot parse your request body.",
 			Err:     err,
-		})
+		}
 	}
 }
 
@@ -46,43 +103,116 @@ func ReadJSON(r *http.Request, v interface{}) {
 // and validation provided by ozzo. It panics with a 400 if the value fails
 // ozzo validation or any other error
 func QueryParam(r *http.Request, v interface{}) {
-	err := requests.QueryParams(r, v)
-	if err == nil {
-		return
+	if err := TryQueryParam(r, v); err != nil {
+		panic(err)
 	}
+}
 
-	var e validation.Errors
-	switch {
-	case errors.As(err, &e):
-		panic(Err{
-			Code:    http.StatusBadRequest,
-			Message: "We could not validate your request.",
-			Data:    e,
-		})
-	default:
-		panic(Err{
-			Code:    http.StatusBadRequest,
-			Message: "We cannot parse your request body.",
-			Err:     err,
-		})
+// TryQueryParam is QueryParam that returns the Err rather than panic.
+func TryQueryParam(r *http.Request, v interface{}) error {
+	if err := requests.QueryParams(r, v); err != nil {
+		return queryErr(err)
 	}
+
+	return nil
 }
 
 // IDParam extracts a uint URL parameter from the given request. panics with a 400 if
 // the param is not a strin, otherwise it panics with a basic error.
 func IDParam(r *http.Request, name string) uint {
+	id, err := TryIDParam(r, name)
+	if err != nil {
+		panic(err)
+	}
+
+	return id
+}
+
+// TryIDParam is IDParam that returns the Err rather than panic. Note that it still
+// panics if the param is not part of the route.
+func TryIDParam(r *http.Request, name string) (uint, error) {
 	id, err := requests.IDParam(r, name)
 	if err != nil {
-		panic(Err{
+		return 0, Err{
 			Code:    http.StatusBadRequest,
 			Message: fmt.Sprintf("%s must be an integer ID", name),
-		})
+		}
 	}
 
-	return id
+	return id, nil
+}
+
+// Param parses the chi URL param into T(see requests.Param), panicking with a 400
+// if it can't. It still panics with a basic error if the param is not part of the route.
+func Param[T any](r *http.Request, name string) T {
+	v, err := TryParam[T](r, name)
+	if err != nil {
+		panic(err)
+	}
+
+	return v
+}
+
+// TryParam is Param that returns the Err rather than panic.
+func TryParam[T any](r *http.Request, name string) (T, error) {
+	v, err := requests.Param[T](r, name)
+	if err != nil {
+		return v, Err{
+			Code:    http.StatusBadRequest,
+			Message: err.Error(),
+			Err:     err,
+		}
+	}
+
+	return v, nil
+}
+
+// ReadCriteria parses the filter and sort query params of the request using the
+// allowlist of fields. It panics with a 400 if the query uses fields or filters that
+// are not allowed.
+func ReadCriteria(r *http.Request, fields requests.Fields) requests.Criteria {
+	c, err := TryReadCriteria(r, fields)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+// TryReadCriteria is ReadCriteria that returns the Err rather than panic.
+func TryReadCriteria(r *http.Request, fields requests.Fields) (requests.Criteria, error) {
+	c, err := requests.ReadCriteria(r, fields)
+	if err != nil {
+		return c, queryErr(err)
+	}
+
+	return c, nil
 }
 
 // StringParam basically just ensures the param name is correct. You might not
 // need this method unless you're too lazy to do real tests. Panics if there's no param
 // with the name.
 var StringParam = requests.StringParam
+
+// queryErr converts errors from reading query params to a 400 Err
+func queryErr(err error) error {
+	var e validation.Errors
+	var bindErr *requests.BindError
+	if errors.As(err, &bindErr) {
+		return conversionErr(bindErr)
+	}
+
+	if errors.As(err, &e) {
+		return Err{
+			Code:    http.StatusBadRequest,
+			Message: "We could not validate your request.",
+			Data:    e,
+		}
+	}
+
+	return Err{
+		Code:    http.StatusBadRequest,
+		Message: "We cannot parse your request.",
+		Err:     err,
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/go-chi/chi/v5"
 	ozzo "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/noxecane/anansi/json"
+	"github.com/noxecane/anansi/requests"
 	"syreclabs.com/go/faker"
 )
 
@@ -103,6 +104,21 @@ func TestReadJSON(t *testing.T) {
 		ReadJSON(req, &n)
 	})
 
+	t.Run("panics with 413 when the body is too large", func(t *testing.T) {
+		defer checkErr(t, http.StatusRequestEntityTooLarge, false, false, "Your request body cannot be larger than 8 bytes.")
+
+		data := `{ "name": "Yuko Omo" }`
+		req := httptest.NewRequest("POST", "http://www.example.com", strings.NewReader(data))
+		req.Header.Add("Content-type", "application/json; charset=utf-8")
+
+		router := chi.NewRouter()
+		router.With(requests.Decoding(requests.DecodeOptions{MaxBytes: 8})).Post("/", func(w http.ResponseWriter, r *http.Request) {
+			var n noValidation
+			ReadJSON(r, &n)
+		})
+		router.ServeHTTP(httptest.NewRecorder(), req)
+	})
+
 	t.Run("fails with validation error", func(t *testing.T) {
 		message := "We could not validate your request."
 		defer checkErr(t, http.StatusBadRequest, false, true, message)
@@ -127,3 +143,50 @@ func TestReadJSON(t *testing.T) {
 		ReadJSON(req, &m)
 	})
 }
+
+func TestBind(t *testing.T) {
+	type bookUpdate struct {
+		ID   uint64 `path:"id" json:"-"`
+		Name string `json:"name"`
+	}
+
+	bind := func(path string) (b bookUpdate) {
+		router := chi.NewRouter()
+		router.Put("/books/{id}", func(w http.ResponseWriter, r *http.Request) {
+			Bind(r, &b)
+		})
+
+		req := httptest.NewRequest("PUT", path, strings.NewReader(`{"name": "Arrow of God"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		return b
+	}
+
+	t.Run("binds the path and body", func(t *testing.T) {
+		b := bind("/books/42")
+
+		if b.ID != 42 || b.Name != "Arrow of God" {
+			t.Errorf("Expected the book to be bound from the request, got %+v", b)
+		}
+	})
+
+	t.Run("panics with 400 for values of the wrong type", func(t *testing.T) {
+		defer checkErr(t, http.StatusBadRequest, false, true, "We could not validate your request.")
+
+		bind("/books/forty-two")
+	})
+}
+
+func TestParam(t *testing.T) {
+	router := chi.NewRouter()
+	router.Get("/books/{slug}", func(w http.ResponseWriter, r *http.Request) {
+		Param[requests.Slug](r, "slug")
+	})
+
+	t.Run("panics with 400 for invalid params", func(t *testing.T) {
+		defer checkErr(t, http.StatusBadRequest, false, false, "slug must be a valid slug")
+
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/books/Things%20Fall%20Apart", nil))
+	})
+}
@@ -1,60 +1,151 @@
 package api
 
 import (
-	"bytes"
-	jsonslow "encoding/json"
 	"net/http"
+	"strings"
 
 	"github.com/noxecane/anansi"
 	"github.com/noxecane/anansi/json"
+	"github.com/noxecane/anansi/requests"
 	"github.com/noxecane/anansi/responses"
 	"github.com/rs/zerolog"
 )
 
 // Success sends a JSend success message with status code 200. It logs the response
-// if a zerolog.Logger is attached to the request.
+// if a zerolog.Logger is attached to the request and the request's requests.LogPolicy
+// doesn't skip it.
 func Success(r *http.Request, w http.ResponseWriter, v interface{}) {
+	Send(r, w, http.StatusOK, v)
+}
+
+// Send is Success with a different status code. The response is encoded in the
+// format the client prefers according to its Accept header(see responses.Negotiate),
+// responding with a 406 if none of them can represent it. Successful responses get an ETag
+// if one has been configured with ETags or WithETags, or set by the handler, and
+// GET requests whose If-None-Match matches it get a 304 without the body. The same goes
+// for If-Modified-Since when the handler sets Last-Modified(see LastModified).
+func Send(r *http.Request, w http.ResponseWriter, code int, v interface{}) {
 	log := zerolog.Ctx(r.Context())
-	raw := getJSON(log, v)
 
-	responses.Send(w, http.StatusOK, raw)
+	contentType, raw, err := responses.Negotiate(r.Header.Get("Accept"), v)
+	if err != nil {
+		Error(r, w, Err{
+			Code:    http.StatusNotAcceptable,
+			Message: "We cannot represent this response in any of the formats you accept.",
+			Err:     err,
+		})
+		return
+	}
+
+	// responses are always logged as JSON
+	if isJSON(contentType) {
+		logJSON(log, v, raw)
+	} else {
+		getJSON(log, v)
+	}
+
+	w.Header().Add("Vary", "Accept")
+
+	if code == http.StatusOK && notModified(r, w, raw) {
+		code = http.StatusNotModified
+		raw = nil
+		w.WriteHeader(code)
+	} else {
+		responses.SendAs(w, code, contentType, raw)
+	}
+
+	if responses.AccessLogged(r) || requests.SkipLog(r, code) {
+		return
+	}
 
 	log.Info().
-		Int("status", http.StatusOK).
+		Int("status", code).
 		Int("length", len(raw)).
-		Interface("response_headers", anansi.SimpleMap(w.Header())).
+		Interface("response_headers", anansi.RedactHeaders(w.Header())).
 		Msg("")
 }
 
-// Error sends a JSend error message. It logs the response if a zerolog.Logger is attached to the request.
+// NoContent sends an empty response with status code 204. It logs the response
+// if a zerolog.Logger is attached to the request and the request's requests.LogPolicy
+// doesn't skip it.
+func NoContent(r *http.Request, w http.ResponseWriter) {
+	log := zerolog.Ctx(r.Context())
+
+	w.WriteHeader(http.StatusNoContent)
+
+	if responses.AccessLogged(r) || requests.SkipLog(r, http.StatusNoContent) {
+		return
+	}
+
+	log.Info().
+		Int("status", http.StatusNoContent).
+		Int("length", 0).
+		Interface("response_headers", anansi.RedactHeaders(w.Header())).
+		Msg("")
+}
+
+// Error sends a JSend error message, or problem details if they've been enabled with
+// ProblemDetails or the Problems middleware. It logs the response if a zerolog.Logger
+// is attached to the request, or hands the error to the request's responses.AccessLog.
 func Error(r *http.Request, w http.ResponseWriter, err Err) {
 	log := zerolog.Ctx(r.Context())
-	raw := getJSON(log, err)
 
-	responses.Send(w, err.Code, raw)
+	if err.Kind == "" {
+		err.Kind = statusKind(err.Code)
+	}
+
+	contentType, raw, nerr := responses.Negotiate(r.Header.Get("Accept"), err)
+	switch {
+	case useProblems(r) && (nerr != nil || isJSON(contentType)):
+		contentType = ProblemContentType + "; charset=utf-8"
+		raw = getJSON(log, NewProblem(r, err))
+	case nerr != nil:
+		// clients get errors even when they can't accept them
+		contentType = "application/json; charset=utf-8"
+		raw = getJSON(log, err)
+	case isJSON(contentType):
+		logJSON(log, err, raw)
+	default:
+		getJSON(log, err)
+	}
+
+	w.Header().Add("Vary", "Accept")
+	responses.SendAs(w, err.Code, contentType, raw)
+
+	if responses.LogError(r, err) {
+		return
+	}
 
 	log.Err(err).
 		Int("status", err.Code).
 		Int("length", len(raw)).
-		Interface("response_headers", anansi.SimpleMap(w.Header())).
+		Interface("response_headers", anansi.RedactHeaders(w.Header())).
 		Msg("")
 }
 
 func getJSON(log *zerolog.Logger, v interface{}) []byte {
 	raw, _ := json.Marshal(v)
+	logJSON(log, v, raw)
 
-	// log API responses
-	if v != nil {
-		log.UpdateContext(func(ctx zerolog.Context) zerolog.Context {
-			buffer := new(bytes.Buffer)
+	return raw
+}
 
-			if err := jsonslow.Compact(buffer, raw); err != nil {
-				panic(err)
-			}
+// logJSON adds the JSON of the response to the request log, without sensitive values
+func logJSON(log *zerolog.Logger, v interface{}, raw []byte) {
+	if v == nil {
+		return
+	}
 
-			return ctx.RawJSON("response", buffer.Bytes())
-		})
+	redacted, err := anansi.RedactJSON(raw, v)
+	if err != nil {
+		panic(err)
 	}
 
-	return raw
+	log.UpdateContext(func(ctx zerolog.Context) zerolog.Context {
+		return ctx.RawJSON("response", redacted)
+	})
+}
+
+func isJSON(contentType string) bool {
+	return strings.HasPrefix(contentType, "application/json")
 }
@@ -5,11 +5,14 @@ import (
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
 	"github.com/noxecane/anansi/json"
 	"github.com/noxecane/anansi/requests"
+	"github.com/noxecane/anansi/responses"
 	"github.com/rs/zerolog"
 	"syreclabs.com/go/faker"
 )
@@ -79,6 +82,38 @@ func TestSuccess(t *testing.T) {
 			t.Error("Expected response headers to be logged")
 		}
 	})
+
+	t.Run("leaves completion to the access log", func(t *testing.T) {
+		logOut.Reset()
+
+		accessRouter := chi.NewRouter()
+		accessRouter.Use(requests.AttachLogger(zerolog.New(logOut)))
+		accessRouter.Use(responses.AccessLog(nil))
+		accessRouter.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			Success(r, w, message{name})
+		})
+		accessRouter.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		if n := strings.Count(logOut.String(), "\n"); n != 1 {
+			t.Errorf("Expected one log line, got %d", n)
+		}
+
+		if !strings.Contains(logOut.String(), name) {
+			t.Errorf("Expected the response to be logged, got %s", logOut.String())
+		}
+	})
+
+	t.Run("skips probes", func(t *testing.T) {
+		logOut.Reset()
+
+		req := httptest.NewRequest("GET", "/logged", nil)
+		req.Header.Set("User-Agent", "kube-probe/1.29")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		if logOut.Len() != 0 {
+			t.Errorf("Expected no log entry, got %s", logOut.String())
+		}
+	})
 }
 
 func TestErr(t *testing.T) {
@@ -166,3 +201,130 @@ func TestErr(t *testing.T) {
 		}
 	})
 }
+
+func TestProblems(t *testing.T) {
+	router := chi.NewRouter()
+	errMessage := "Cannot process request"
+
+	router.Use(middleware.RequestID)
+	router.With(Problems).Get("/", func(w http.ResponseWriter, r *http.Request) {
+		Error(r, w, Err{
+			Code:       http.StatusUnprocessableEntity,
+			Message:    errMessage,
+			Extensions: map[string]interface{}{"balance": 30},
+		})
+	})
+
+	t.Run("sends problem details", func(t *testing.T) {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		router.ServeHTTP(res, req)
+
+		if res.Code != http.StatusUnprocessableEntity {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusUnprocessableEntity, res.Code)
+		}
+
+		if !strings.HasPrefix(res.Header().Get("Content-Type"), ProblemContentType) {
+			t.Errorf("Expected the content type to be %s, got %s", ProblemContentType, res.Header().Get("Content-Type"))
+		}
+
+		var p Problem
+		if err := json.Unmarshal(res.Body.Bytes(), &p); err != nil {
+			t.Fatal(err)
+		}
+
+		if p.Status != http.StatusUnprocessableEntity {
+			t.Errorf("Expected the problem status to be %d, got %d", http.StatusUnprocessableEntity, p.Status)
+		}
+
+		if p.Detail != errMessage {
+			t.Errorf("Expected problem detail to be %s, got %s", errMessage, p.Detail)
+		}
+
+		if p.Type != "about:blank" {
+			t.Errorf("Expected problem type to be about:blank, got %s", p.Type)
+		}
+
+		if p.Instance == "" {
+			t.Error("Expected problem instance to be the request ID")
+		}
+
+		if p.Extensions["balance"] != float64(30) {
+			t.Errorf("Expected balance extension to be 30, got %v", p.Extensions["balance"])
+		}
+	})
+}
+
+func TestNegotiation(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(Recoverer("production"))
+	router.Get("/books", func(w http.ResponseWriter, r *http.Request) {
+		Success(r, w, []myStruct{{Name: "Things fall apart"}, {Name: "Arrow of God"}})
+	})
+	router.Get("/book", func(w http.ResponseWriter, r *http.Request) {
+		Success(r, w, myStruct{Name: "Things fall apart"})
+	})
+	router.Get("/missing", func(w http.ResponseWriter, r *http.Request) {
+		panic(errBookNotFound)
+	})
+
+	send := func(path, accept string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept", accept)
+		router.ServeHTTP(res, req)
+
+		return res
+	}
+
+	t.Run("sends the preferred format", func(t *testing.T) {
+		res := send("/books", "text/csv, application/json;q=0.5")
+
+		if ct := res.Header().Get("Content-Type"); ct != "text/csv; charset=utf-8" {
+			t.Errorf("Expected the content type to be %s, got %s", "text/csv; charset=utf-8", ct)
+		}
+
+		if res.Body.String() != "name\nThings fall apart\nArrow of God\n" {
+			t.Errorf("Expected a CSV body, got %q", res.Body.String())
+		}
+
+		if res.Header().Get("Vary") != "Accept" {
+			t.Errorf("Expected the Vary header to be %s, got %s", "Accept", res.Header().Get("Vary"))
+		}
+	})
+
+	t.Run("falls through formats that can't represent the response", func(t *testing.T) {
+		res := send("/book", "text/csv, application/json;q=0.5")
+
+		if ct := res.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+			t.Errorf("Expected the content type to be %s, got %s", "application/json; charset=utf-8", ct)
+		}
+	})
+
+	t.Run("responds with 406 when nothing matches", func(t *testing.T) {
+		res := send("/book", "text/csv")
+
+		if res.Code != http.StatusNotAcceptable {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusNotAcceptable, res.Code)
+		}
+
+		if ct := res.Header().Get("Content-Type"); ct != "application/json; charset=utf-8" {
+			t.Errorf("Expected the error to be sent as JSON, got %s", ct)
+		}
+	})
+
+	t.Run("sends errors in the preferred format", func(t *testing.T) {
+		responses.RegisterEncoder("application/xml", responses.XML)
+
+		res := send("/missing", "application/xml")
+
+		if ct := res.Header().Get("Content-Type"); ct != "application/xml; charset=utf-8" {
+			t.Errorf("Expected the content type to be %s, got %s", "application/xml; charset=utf-8", ct)
+		}
+
+		expected := "<error><code>book_not_found</code><message>We could not find your book</message></error>"
+		if res.Body.String() != expected {
+			t.Errorf("Expected the body to be %s, got %s", expected, res.Body.String())
+		}
+	})
+}
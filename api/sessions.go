@@ -7,120 +7,81 @@ import (
 )
 
 func LoadBearer(m *sessions.Manager, r *http.Request, v interface{}) {
-	err := m.FromAuth(r, v)
-	if err == nil {
-		return
+	if err := TryLoadBearer(m, r, v); err != nil {
+		panic(err)
 	}
+}
 
-	switch err {
-	case sessions.ErrEmptyHeader:
-		panic(Err{
-			Code:    http.StatusUnauthorized,
-			Message: "Your request is not authenticated",
-		})
-	case sessions.ErrHeaderFormat:
-		panic(Err{
-			Code:    http.StatusUnauthorized,
-			Message: "Your authorization header is incorrect",
-		})
-	case sessions.ErrUnsupportedScheme:
-		panic(Err{
-			Code:    http.StatusUnauthorized,
-			Message: "We don't support your authorization scheme",
-		})
-	default:
-		panic(Err{
-			Code:    http.StatusUnauthorized,
-			Message: "Your token is either invalid or has expired",
-			Err:     err,
-		})
-	}
+// TryLoadBearer is LoadBearer that returns the Err rather than panic.
+func TryLoadBearer(m *sessions.Manager, r *http.Request, v interface{}) error {
+	return sessionErr(m.FromAuth(r, v))
 }
 
 func LoadCookie(m *sessions.Manager, r *http.Request, v interface{}) {
-	err := m.FromCookie(r, v)
-	if err == nil {
-		return
+	if err := TryLoadCookie(m, r, v); err != nil {
+		panic(err)
 	}
+}
 
-	switch err {
-	case sessions.ErrEmptyAuthCookie:
-		panic(Err{
-			Code:    http.StatusUnauthorized,
-			Message: "Your request is not authenticated",
-		})
-	default:
-		panic(Err{
-			Code:    http.StatusUnauthorized,
-			Message: "Your token is either invalid or has expired",
-			Err:     err,
-		})
-	}
+// TryLoadCookie is LoadCookie that returns the Err rather than panic.
+func TryLoadCookie(m *sessions.Manager, r *http.Request, v interface{}) error {
+	return sessionErr(m.FromCookie(r, v))
 }
 
 func LoadHeadless(m *sessions.Manager, r *http.Request, v interface{}) {
-	err := m.FromAuth(r, v)
-	if err == nil {
-		return
+	if err := TryLoadHeadless(m, r, v); err != nil {
+		panic(err)
 	}
+}
 
-	switch err {
-	case sessions.ErrEmptyHeader:
-		panic(Err{
-			Code:    http.StatusUnauthorized,
-			Message: "Your request is not authenticated",
-		})
-	case sessions.ErrHeaderFormat:
-		panic(Err{
-			Code:    http.StatusUnauthorized,
-			Message: "Your authorization header is incorrect",
-		})
-	case sessions.ErrUnsupportedScheme:
-		panic(Err{
-			Code:    http.StatusUnauthorized,
-			Message: "We don't support your authorization scheme",
-		})
-	default:
-		panic(Err{
-			Code:    http.StatusUnauthorized,
-			Message: "Your token is either invalid or has expired",
-			Err:     err,
-		})
-	}
+// TryLoadHeadless is LoadHeadless that returns the Err rather than panic.
+func TryLoadHeadless(m *sessions.Manager, r *http.Request, v interface{}) error {
+	return sessionErr(m.FromAuth(r, v))
 }
 
 func Load(m *sessions.Manager, r *http.Request, v interface{}) {
-	err := m.Load(r, v)
+	if err := TryLoad(m, r, v); err != nil {
+		panic(err)
+	}
+}
+
+// TryLoad is Load that returns the Err rather than panic.
+func TryLoad(m *sessions.Manager, r *http.Request, v interface{}) error {
+	return sessionErr(m.Load(r, v))
+}
+
+// sessionErr converts errors from loading sessions to a 401 Err
+func sessionErr(err error) error {
 	if err == nil {
-		return
+		return nil
 	}
 
 	switch err {
 	case sessions.ErrEmptyAuthCookie:
-		panic(Err{
+		return Err{
 			Code:    http.StatusUnauthorized,
 			Message: "Your request is not authenticated",
-		})
+		}
 	case sessions.ErrEmptyHeader:
-		panic(Err{
+		return Err{
 			Code:    http.StatusUnauthorized,
 			Message: "Your request is not authenticated",
-		})
+		}
 	case sessions.ErrHeaderFormat:
-		panic(Err{
+		return Err{
 			Code:    http.StatusUnauthorized,
 			Message: "Your authorization header is incorrect",
-		})
+		}
 	case sessions.ErrUnsupportedScheme:
-		panic(Err{
+		return Err{
 			Code:    http.StatusUnauthorized,
 			Message: "We don't support your authorization scheme",
-		})
+		}
 	default:
-		panic(Err{
+		return Err{
 			Code:    http.StatusUnauthorized,
 			Message: "Your token is either invalid or has expired",
 			Err:     err,
-		})
+		}
 	}
 }
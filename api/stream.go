@@ -0,0 +1,154 @@
+package api
+
+import (
+	"bufio"
+	"iter"
+	"net/http"
+	"strings"
+
+	"github.com/noxecane/anansi"
+	"github.com/noxecane/anansi/json"
+	"github.com/noxecane/anansi/requests"
+	"github.com/noxecane/anansi/responses"
+	"github.com/rs/zerolog"
+)
+
+// NDJSONContentType is the content type of newline-delimited JSON responses
+const NDJSONContentType = "application/x-ndjson"
+
+// StreamFlushSize is the number of items Stream writes before flushing them to the client.
+var StreamFlushSize = 100
+
+// Stream writes the items of seq as they are produced, rather than building the
+// entire response in memory like Success does. Clients that accept application/x-ndjson
+// get newline-delimited JSON, every other client gets a JSON array. Only the number
+// of items and the size of the response are logged.
+//
+// Since the status has already been sent, Stream aborts the response(see http.ErrAbortHandler)
+// if the request context is done, the client goes away or an item can't be marshalled,
+// so clients never mistake a partial response for a complete one. Aborted streams are
+// logged as errors.
+func Stream[T any](r *http.Request, w http.ResponseWriter, seq iter.Seq[T]) {
+	log := zerolog.Ctx(r.Context())
+	ctx := r.Context()
+	rc := http.NewResponseController(w)
+	ndjson := strings.Contains(r.Header.Get("Accept"), NDJSONContentType)
+
+	if ndjson {
+		w.Header().Set("Content-Type", NDJSONContentType+"; charset=utf-8")
+	} else {
+		w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	}
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.Header().Del("Content-Length")
+	w.WriteHeader(http.StatusOK)
+
+	cw := &countingWriter{w: w}
+	bw := bufio.NewWriter(cw)
+	count := 0
+
+	// the reason the response was aborted, if it was
+	var aborted error
+	abort := func(err error) {
+		aborted = err
+		panic(http.ErrAbortHandler)
+	}
+
+	defer func() {
+		if responses.AccessLogged(r) {
+			log.UpdateContext(func(ctx zerolog.Context) zerolog.Context {
+				return ctx.Int("count", count)
+			})
+
+			if aborted != nil {
+				responses.LogError(r, aborted)
+			}
+			return
+		}
+
+		if aborted != nil {
+			log.Err(aborted).
+				Int("count", count).
+				Int("length", cw.n).
+				Msg("aborted stream")
+			return
+		}
+
+		if requests.SkipLog(r, http.StatusOK) {
+			return
+		}
+
+		log.Info().
+			Int("status", http.StatusOK).
+			Int("count", count).
+			Int("length", cw.n).
+			Interface("response_headers", anansi.RedactHeaders(w.Header())).
+			Msg("")
+	}()
+
+	flush := func() error {
+		if err := bw.Flush(); err != nil {
+			return err
+		}
+
+		// not every writer can flush, buffering is the best we can do for those
+		if err := rc.Flush(); err != nil && err != http.ErrNotSupported {
+			return err
+		}
+
+		return nil
+	}
+
+	if !ndjson {
+		_ = bw.WriteByte('[')
+	}
+
+	for item := range seq {
+		if err := ctx.Err(); err != nil {
+			abort(err)
+		}
+
+		raw, err := json.Marshal(item)
+		if err != nil {
+			abort(err)
+		}
+
+		if !ndjson && count > 0 {
+			_ = bw.WriteByte(',')
+		}
+		_, _ = bw.Write(raw)
+		if ndjson {
+			_ = bw.WriteByte('\n')
+		}
+		count++
+
+		if count%StreamFlushSize == 0 {
+			if err := flush(); err != nil {
+				abort(err)
+			}
+		}
+	}
+
+	if err := ctx.Err(); err != nil {
+		abort(err)
+	}
+
+	if !ndjson {
+		_ = bw.WriteByte(']')
+	}
+
+	_ = flush()
+}
+
+// countingWriter tracks the number of bytes written to the response
+type countingWriter struct {
+	w http.ResponseWriter
+	n int
+}
+
+func (cw *countingWriter) Write(buf []byte) (int, error) {
+	n, err := cw.w.Write(buf)
+	cw.n += n
+
+	return n, err
+}
@@ -0,0 +1,179 @@
+package api
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"io"
+	"iter"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/noxecane/anansi/json"
+	"github.com/rs/zerolog"
+)
+
+func books(n int) iter.Seq[myStruct] {
+	return func(yield func(myStruct) bool) {
+		for i := 0; i < n; i++ {
+			if !yield(myStruct{Name: "Things fall apart"}) {
+				return
+			}
+		}
+	}
+}
+
+func TestStream(t *testing.T) {
+	out := new(bytes.Buffer)
+	log := zerolog.New(out)
+
+	router := chi.NewRouter()
+	router.Get("/books", func(w http.ResponseWriter, r *http.Request) {
+		r = r.WithContext(log.WithContext(r.Context()))
+		Stream(r, w, books(250))
+	})
+	router.Get("/cancelled", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithCancel(log.WithContext(r.Context()))
+		r = r.WithContext(ctx)
+
+		Stream(r, w, func(yield func(myStruct) bool) {
+			for range books(150) {
+				if !yield(myStruct{Name: "Arrow of God"}) {
+					return
+				}
+			}
+			cancel()
+
+			for range books(10) {
+				if !yield(myStruct{Name: "Arrow of God"}) {
+					return
+				}
+			}
+		})
+	})
+	router.Get("/empty", func(w http.ResponseWriter, r *http.Request) {
+		Stream(r, w, books(0))
+	})
+
+	send := func(path, accept string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("Accept", accept)
+		router.ServeHTTP(res, req)
+
+		return res
+	}
+
+	t.Run("writes a JSON array", func(t *testing.T) {
+		res := send("/books", "application/json")
+
+		var items []myStruct
+		if err := json.Unmarshal(res.Body.Bytes(), &items); err != nil {
+			t.Fatal(err)
+		}
+
+		if len(items) != 250 {
+			t.Errorf("Expected %d items, got %d", 250, len(items))
+		}
+
+		if !res.Flushed {
+			t.Error("Expected the response to be flushed")
+		}
+	})
+
+	t.Run("writes an empty JSON array", func(t *testing.T) {
+		res := send("/empty", "application/json")
+
+		if res.Body.String() != "[]" {
+			t.Errorf("Expected the body to be %s, got %s", "[]", res.Body.String())
+		}
+	})
+
+	t.Run("writes newline-delimited JSON", func(t *testing.T) {
+		res := send("/books", NDJSONContentType)
+
+		if ct := res.Header().Get("Content-Type"); ct != NDJSONContentType+"; charset=utf-8" {
+			t.Errorf("Expected the content type to be %s, got %s", NDJSONContentType, ct)
+		}
+
+		lines := 0
+		scanner := bufio.NewScanner(res.Body)
+		for scanner.Scan() {
+			var m myStruct
+			if err := json.Unmarshal(scanner.Bytes(), &m); err != nil {
+				t.Fatal(err)
+			}
+			lines++
+		}
+
+		if lines != 250 {
+			t.Errorf("Expected %d lines, got %d", 250, lines)
+		}
+	})
+
+	t.Run("aborts when the context is done", func(t *testing.T) {
+		out.Reset()
+
+		defer func() {
+			if rvr := recover(); rvr != http.ErrAbortHandler {
+				t.Errorf("Expected the response to be aborted, got %v", rvr)
+			}
+
+			var entry map[string]interface{}
+			if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+				t.Fatal(err)
+			}
+
+			if entry["level"] != "error" || entry["count"] != float64(150) {
+				t.Errorf("Expected the abort to be logged as an error after 150 items, got %v", entry)
+			}
+		}()
+
+		send("/cancelled", NDJSONContentType)
+	})
+
+	t.Run("cuts the connection behind Recoverer", func(t *testing.T) {
+		server := httptest.NewServer(Recoverer("production")(router))
+		defer server.Close()
+
+		req, err := http.NewRequest("GET", server.URL+"/cancelled", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Accept", NDJSONContentType)
+
+		res, err := server.Client().Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer res.Body.Close()
+
+		if _, err := io.ReadAll(res.Body); err == nil {
+			t.Error("Expected the client to get an error reading the aborted response")
+		}
+	})
+
+	t.Run("logs only counts and sizes", func(t *testing.T) {
+		out.Reset()
+		res := send("/books", "application/json")
+
+		var entry map[string]interface{}
+		if err := json.Unmarshal(out.Bytes(), &entry); err != nil {
+			t.Fatal(err)
+		}
+
+		if entry["count"] != float64(250) {
+			t.Errorf("Expected the count to be logged as %d, got %v", 250, entry["count"])
+		}
+
+		if entry["length"] != float64(res.Body.Len()) {
+			t.Errorf("Expected the length to be logged as %d, got %v", res.Body.Len(), entry["length"])
+		}
+
+		if _, ok := entry["response"]; ok {
+			t.Error("Expected the response not to be logged")
+		}
+	})
+}
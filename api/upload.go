@@ -0,0 +1,83 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/noxecane/anansi/requests"
+	"github.com/noxecane/anansi/storage"
+)
+
+// Upload streams the files of a multipart form to the store and binds the rest of the
+// fields into v(see requests.Upload). It panics with a 415 if the request is not a
+// multipart form or has a file of a type that's not allowed, a 413 if a file, field or the
+// fields together are too large and a 400 if v fails ozzo validation or any other error.
+func Upload(r *http.Request, store storage.Storage, opts requests.UploadOptions, v interface{}) []requests.File {
+	files, err := TryUpload(r, store, opts, v)
+	if err != nil {
+		panic(err)
+	}
+
+	return files
+}
+
+// TryUpload is Upload that returns the Err rather than panic.
+func TryUpload(r *http.Request, store storage.Storage, opts requests.UploadOptions, v interface{}) ([]requests.File, error) {
+	files, err := requests.Upload(r, store, opts, v)
+	if err == nil {
+		return files, nil
+	}
+
+	var fileErr *requests.FileError
+	var tooLarge *http.MaxBytesError
+	var e validation.Errors
+	var bindErr *requests.BindError
+	switch {
+	case errors.Is(err, http.ErrNotMultipart):
+		return nil, Err{
+			Code:    http.StatusUnsupportedMediaType,
+			Message: http.StatusText(http.StatusUnsupportedMediaType),
+			Err:     err,
+		}
+	case errors.As(err, &fileErr) && fileErr.Err == requests.ErrFileType:
+		return nil, Err{
+			Code:    http.StatusUnsupportedMediaType,
+			Message: fmt.Sprintf("%s is not one of the file types we accept.", fileErr.Filename),
+			Err:     err,
+		}
+	case errors.As(err, &fileErr) && fileErr.Err == requests.ErrFileTooLarge:
+		return nil, Err{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("%s cannot be larger than %d bytes.", fileErr.Filename, opts.MaxFileSize),
+			Err:     err,
+		}
+	case errors.Is(err, requests.ErrFieldTooLarge), errors.Is(err, requests.ErrFormTooLarge), errors.As(err, &tooLarge):
+		return nil, Err{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: http.StatusText(http.StatusRequestEntityTooLarge),
+			Err:     err,
+		}
+	case errors.Is(err, requests.ErrTooManyFiles):
+		return nil, Err{
+			Code:    http.StatusBadRequest,
+			Message: fmt.Sprintf("You cannot upload more than %d files.", opts.MaxFiles),
+			Err:     err,
+		}
+	case errors.As(err, &bindErr):
+		return nil, conversionErr(bindErr)
+	case errors.As(err, &e):
+		return nil, Err{
+			Code:    http.StatusBadRequest,
+			Message: "We could not validate your request.",
+			Data:    e,
+		}
+	default:
+		return nil, Err{
+			Code:    http.StatusBadRequest,
+			Message: "We cannot parse your request body.",
+			Err:     err,
+		}
+	}
+}
@@ -0,0 +1,59 @@
+package api
+
+import (
+	"bytes"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/noxecane/anansi/requests"
+	"github.com/noxecane/anansi/storage"
+)
+
+func TestUpload(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(Recoverer("production"))
+	router.Post("/covers", func(w http.ResponseWriter, r *http.Request) {
+		files := Upload(r, storage.NewMemory(), requests.UploadOptions{
+			MaxFileSize:  16,
+			AllowedTypes: []string{"text/plain"},
+		}, nil)
+		Send(r, w, http.StatusCreated, files)
+	})
+
+	send := func(content string) *httptest.ResponseRecorder {
+		body := new(bytes.Buffer)
+		mw := multipart.NewWriter(body)
+		fw, _ := mw.CreateFormFile("cover", "cover.txt")
+		_, _ = fw.Write([]byte(content))
+		_ = mw.Close()
+
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("POST", "/covers", body)
+		req.Header.Set("Content-Type", mw.FormDataContentType())
+		router.ServeHTTP(res, req)
+
+		return res
+	}
+
+	t.Run("saves files", func(t *testing.T) {
+		if res := send("chinua achebe"); res.Code != http.StatusCreated {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusCreated, res.Code)
+		}
+	})
+
+	t.Run("responds with 413 for large files", func(t *testing.T) {
+		if res := send(strings.Repeat("a", 32)); res.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusRequestEntityTooLarge, res.Code)
+		}
+	})
+
+	t.Run("responds with 415 for files of the wrong type", func(t *testing.T) {
+		if res := send("\x89PNG\r\n\x1a\n"); res.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusUnsupportedMediaType, res.Code)
+		}
+	})
+}
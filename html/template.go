@@ -1,13 +1,13 @@
 package html
 
 import (
-	"bytes"
-	jsonslow "encoding/json"
 	"html/template"
 	"net/http"
 
 	"github.com/noxecane/anansi"
 	"github.com/noxecane/anansi/json"
+	"github.com/noxecane/anansi/requests"
+	"github.com/noxecane/anansi/responses"
 	"github.com/rs/zerolog"
 )
 
@@ -38,29 +38,33 @@ func (t *htmlTemplate) Render(r *http.Request, w http.ResponseWriter, data any)
 	raw, err := json.Marshal(data)
 
 	if data != nil {
-		log.UpdateContext(func(ctx zerolog.Context) zerolog.Context {
-			buffer := new(bytes.Buffer)
-			if err != nil {
-				panic(err)
-			}
+		if err != nil {
+			panic(err)
+		}
 
-			if err := jsonslow.Compact(buffer, raw); err != nil {
-				panic(err)
-			}
+		redacted, err := anansi.RedactJSON(raw, data)
+		if err != nil {
+			panic(err)
+		}
 
-			return ctx.RawJSON("html_data", buffer.Bytes())
+		log.UpdateContext(func(ctx zerolog.Context) zerolog.Context {
+			return ctx.RawJSON("html_data", redacted)
 		})
 	}
 
 	err = t.tmpl.ExecuteTemplate(w, t.name, data)
 
 	if err == nil {
+		if responses.AccessLogged(r) || requests.SkipLog(r, http.StatusOK) {
+			return nil
+		}
+
 		log.Info().
-			Interface("response_headers", anansi.SimpleMap(w.Header())).
+			Interface("response_headers", anansi.RedactHeaders(w.Header())).
 			Msg("")
-	} else {
+	} else if !responses.LogError(r, err) {
 		log.Err(err).
-			Interface("response_headers", anansi.SimpleMap(w.Header())).
+			Interface("response_headers", anansi.RedactHeaders(w.Header())).
 			Msg("")
 	}
 
@@ -0,0 +1,64 @@
+// Package expiring has the map behind the in-memory stores, whose entries expire
+// like redis keys do.
+package expiring
+
+import "time"
+
+type entry[V any] struct {
+	value V
+	until time.Time
+}
+
+// Map is a map whose entries expire. Expired entries are never returned, and are
+// swept out at most once per interval so writes don't have to scan the entire map.
+// It isn't safe for concurrent use.
+type Map[V any] struct {
+	entries   map[string]entry[V]
+	interval  time.Duration
+	lastSweep time.Time
+}
+
+// New creates an empty Map that sweeps expired entries every interval.
+func New[V any](interval time.Duration) *Map[V] {
+	return &Map[V]{
+		entries:   make(map[string]entry[V]),
+		interval:  interval,
+		lastSweep: time.Now(),
+	}
+}
+
+// Get returns the value of key if it hasn't expired by now.
+func (m *Map[V]) Get(key string, now time.Time) (V, bool) {
+	e, ok := m.entries[key]
+	if !ok || now.After(e.until) {
+		var zero V
+		return zero, false
+	}
+
+	return e.value, true
+}
+
+// Set saves v under key until now+ttl.
+func (m *Map[V]) Set(key string, v V, now time.Time, ttl time.Duration) {
+	if now.Sub(m.lastSweep) >= m.interval {
+		for k, e := range m.entries {
+			if now.After(e.until) {
+				delete(m.entries, k)
+			}
+		}
+		m.lastSweep = now
+	}
+
+	m.entries[key] = entry[V]{value: v, until: now.Add(ttl)}
+}
+
+// Delete removes key from the map.
+func (m *Map[V]) Delete(key string) {
+	delete(m.entries, key)
+}
+
+// Len is the number of entries in the map, including those that have expired
+// but haven't been swept.
+func (m *Map[V]) Len() int {
+	return len(m.entries)
+}
@@ -0,0 +1,36 @@
+package expiring
+
+import (
+	"testing"
+	"time"
+)
+
+func TestMap(t *testing.T) {
+	now := time.Now()
+	m := New[int](time.Minute)
+
+	m.Set("short", 1, now, time.Second)
+	m.Set("long", 2, now, time.Hour)
+
+	t.Run("hides expired entries", func(t *testing.T) {
+		if v, ok := m.Get("long", now.Add(time.Minute)); !ok || v != 2 {
+			t.Errorf("Expected long to be 2, got %d", v)
+		}
+
+		if _, ok := m.Get("short", now.Add(2*time.Second)); ok {
+			t.Error("Expected short to have expired")
+		}
+	})
+
+	t.Run("sweeps at most once per interval", func(t *testing.T) {
+		m.Set("other", 3, now.Add(2*time.Second), time.Hour)
+		if m.Len() != 3 {
+			t.Errorf("Expected the expired entry to be kept until the next sweep, got %d entries", m.Len())
+		}
+
+		m.Set("other", 3, now.Add(2*time.Minute), time.Hour)
+		if m.Len() != 2 {
+			t.Errorf("Expected the expired entry to be swept, got %d entries", m.Len())
+		}
+	})
+}
@@ -1,19 +1,27 @@
 package jwt
 
 import (
+	"context"
 	"errors"
 	"time"
 
 	"github.com/go-jose/go-jose/v3"
 	"github.com/go-jose/go-jose/v3/jwt"
 	"github.com/go-viper/mapstructure/v2"
+	"github.com/noxecane/anansi/tokens"
+	"github.com/segmentio/ksuid"
 )
 
 var (
 	ErrJWTExpired   = errors.New("token has expired")
 	ErrInvalidToken = errors.New("token is an invalid")
+	ErrJWTRevoked   = errors.New("token has been revoked")
 )
 
+// Revocations is the list of revoked token IDs checked when decoding tokens.
+// Revocation checks are skipped when it's nil.
+var Revocations tokens.RevocationList
+
 type CustomClaim struct {
 	jwt.Claims
 	CustomClaims interface{} `json:"urn:custom:claims"`
@@ -32,12 +40,16 @@ func Encode(secret []byte, t time.Duration, v interface{}) (string, error) {
 	}
 
 	if c, ok := v.(CustomClaim); ok {
+		if c.ID == "" {
+			c.ID = ksuid.New().String()
+		}
 		c.IssuedAt = jwt.NewNumericDate(time.Now())
 		c.Expiry = jwt.NewNumericDate(time.Now().Add(t))
 
 		return jwt.Encrypted(enc).Claims(c).CompactSerialize()
 	} else {
 		def := jwt.Claims{
+			ID:       ksuid.New().String(),
 			IssuedAt: jwt.NewNumericDate(time.Now()),
 			Expiry:   jwt.NewNumericDate(time.Now().Add(t)),
 		}
@@ -50,16 +62,17 @@ func Encode(secret []byte, t time.Duration, v interface{}) (string, error) {
 // Decodes and decrypts a JWE token. Note that it expects the claim to be wrapped
 // using `urn:custom:claims`. Make sure your secret is at least 32 bytes
 func Decode(secret []byte, token string, v interface{}) error {
-	tok, err := jwt.ParseEncrypted(token)
+	return DecodeContext(context.Background(), secret, token, v)
+}
+
+// DecodeContext is Decode with a context for checking Revocations. It returns
+// ErrJWTRevoked if the token has been revoked.
+func DecodeContext(ctx context.Context, secret []byte, token string, v interface{}) error {
+	claims, err := parse(secret, token)
 	if err != nil {
 		return err
 	}
 
-	var claims CustomClaim
-	if err := tok.Claims(secret, &claims); err != nil {
-		return ErrInvalidToken
-	}
-
 	if err := claims.ValidateWithLeeway(jwt.Expected{Time: time.Now()}, 0); err != nil {
 		if err == jwt.ErrExpired {
 			return ErrJWTExpired
@@ -67,6 +80,17 @@ func Decode(secret []byte, token string, v interface{}) error {
 		return err
 	}
 
+	if Revocations != nil && claims.ID != "" {
+		revoked, err := Revocations.IsRevoked(ctx, claims.ID)
+		if err != nil {
+			return err
+		}
+
+		if revoked {
+			return ErrJWTRevoked
+		}
+	}
+
 	if claims.CustomClaims == nil {
 		return nil
 	}
@@ -80,3 +104,37 @@ func Decode(secret []byte, token string, v interface{}) error {
 
 	return decoder.Decode(claims.CustomClaims)
 }
+
+// Revoke adds the ID of the token to Revocations so it gets rejected until it
+// expires. It does nothing if Revocations is not set, the token has already expired
+// or the token has no ID(i.e. it wasn't created by Encode).
+func Revoke(ctx context.Context, secret []byte, token string) error {
+	if Revocations == nil {
+		return nil
+	}
+
+	claims, err := parse(secret, token)
+	if err != nil {
+		return err
+	}
+
+	if claims.ID == "" || claims.Expiry == nil {
+		return nil
+	}
+
+	return Revocations.Revoke(ctx, claims.ID, time.Until(claims.Expiry.Time()))
+}
+
+func parse(secret []byte, token string) (*CustomClaim, error) {
+	tok, err := jwt.ParseEncrypted(token)
+	if err != nil {
+		return nil, err
+	}
+
+	var claims CustomClaim
+	if err := tok.Claims(secret, &claims); err != nil {
+		return nil, ErrInvalidToken
+	}
+
+	return &claims, nil
+}
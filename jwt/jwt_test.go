@@ -1,9 +1,11 @@
 package jwt
 
 import (
+	"context"
 	"testing"
 	"time"
 
+	"github.com/noxecane/anansi/tokens"
 	"syreclabs.com/go/faker"
 )
 
@@ -55,3 +57,47 @@ func TestEncodeDecode(t *testing.T) {
 		}
 	})
 }
+
+func TestRevoke(t *testing.T) {
+	secret := []byte("Die8ohsuyahno5dohL6oofaiShie3fie")
+	ctx := context.TODO()
+
+	Revocations = tokens.NewMemoryRevocationList()
+	defer func() { Revocations = nil }()
+
+	t.Run("should reject revoked tokens", func(t *testing.T) {
+		token, err := Encode(secret, time.Minute, jwtStruct{faker.Name().FirstName()})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := Revoke(ctx, secret, token); err != nil {
+			t.Fatal(err)
+		}
+
+		err = DecodeContext(ctx, secret, token, &jwtStruct{})
+		if err != ErrJWTRevoked {
+			t.Errorf("Expected Decode to fail with ErrJWTRevoked, failed with %v", err)
+		}
+	})
+
+	t.Run("should not affect other tokens", func(t *testing.T) {
+		revoked, err := Encode(secret, time.Minute, jwtStruct{faker.Name().FirstName()})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		token, err := Encode(secret, time.Minute, jwtStruct{faker.Name().FirstName()})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if err := Revoke(ctx, secret, revoked); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := Decode(secret, token, &jwtStruct{}); err != nil {
+			t.Errorf("Expected Decode to succeed, failed with %v", err)
+		}
+	})
+}
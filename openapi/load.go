@@ -0,0 +1,111 @@
+package openapi
+
+import (
+	"bytes"
+	jsonslow "encoding/json"
+	"io"
+	"os"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// Load reads an OpenAPI 3 document written in either JSON or YAML. Parameters
+// defined for a path are copied to each of its operations.
+func Load(r io.Reader) (*Document, error) {
+	raw, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	// only YAML documents need to be converted to JSON
+	if !bytes.HasPrefix(bytes.TrimSpace(raw), []byte("{")) {
+		var v any
+		if err := yaml.Unmarshal(raw, &v); err != nil {
+			return nil, err
+		}
+
+		if raw, err = jsonslow.Marshal(v); err != nil {
+			return nil, err
+		}
+	}
+
+	var loaded struct {
+		OpenAPI    string                                    `json:"openapi"`
+		Info       Info                                      `json:"info"`
+		Paths      map[string]map[string]jsonslow.RawMessage `json:"paths"`
+		Components Components                                `json:"components"`
+	}
+	if err := jsonslow.Unmarshal(raw, &loaded); err != nil {
+		return nil, err
+	}
+
+	doc := &Document{
+		OpenAPI:    loaded.OpenAPI,
+		Info:       loaded.Info,
+		Paths:      make(map[string]map[string]*Operation),
+		Components: loaded.Components,
+	}
+
+	for path, item := range loaded.Paths {
+		var shared []Parameter
+		if params, ok := item["parameters"]; ok {
+			if err := jsonslow.Unmarshal(params, &shared); err != nil {
+				return nil, err
+			}
+		}
+
+		doc.Paths[path] = make(map[string]*Operation)
+		for _, method := range methods {
+			rawOp, ok := item[method]
+			if !ok {
+				continue
+			}
+
+			op := new(Operation)
+			if err := jsonslow.Unmarshal(rawOp, op); err != nil {
+				return nil, err
+			}
+
+			op.Parameters = mergeParams(shared, op.Parameters)
+			doc.Paths[path][method] = op
+		}
+	}
+
+	return doc, nil
+}
+
+// LoadFile reads the OpenAPI 3 document at path.
+func LoadFile(path string) (*Document, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	return Load(f)
+}
+
+// mergeParams adds the path parameters to those of an operation, unless the
+// operation overrides them.
+func mergeParams(shared, own []Parameter) []Parameter {
+	params := append([]Parameter{}, own...)
+
+	for _, p := range shared {
+		overridden := false
+		for _, o := range own {
+			if o.Ref == "" && p.Ref == "" && strings.EqualFold(o.Name, p.Name) && o.In == p.In {
+				overridden = true
+				break
+			}
+		}
+
+		if !overridden {
+			params = append(params, p)
+		}
+	}
+
+	return params
+}
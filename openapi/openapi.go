@@ -0,0 +1,260 @@
+package openapi
+
+import (
+	"net/http"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/noxecane/anansi/api"
+	"github.com/noxecane/anansi/json"
+	"github.com/noxecane/anansi/responses"
+)
+
+// Version is the version of OpenAPI documents generated
+const Version = "3.0.3"
+
+var pathParam = regexp.MustCompile(`\{([^}:]+)(:[^}]*)?\}`)
+
+// Info is the metadata of the API
+type Info struct {
+	Title       string `json:"title"`
+	Description string `json:"description,omitempty"`
+	Version     string `json:"version"`
+}
+
+// Document is an OpenAPI 3 document
+type Document struct {
+	OpenAPI    string                           `json:"openapi"`
+	Info       Info                             `json:"info"`
+	Paths      map[string]map[string]*Operation `json:"paths"`
+	Components Components                       `json:"components"`
+}
+
+// Components holds the schemas and parameters shared by operations
+type Components struct {
+	Schemas    map[string]*Schema    `json:"schemas,omitempty"`
+	Parameters map[string]*Parameter `json:"parameters,omitempty"`
+}
+
+// Operation documents a single API operation on a path
+type Operation struct {
+	OperationID string               `json:"operationId,omitempty"`
+	Summary     string               `json:"summary,omitempty"`
+	Description string               `json:"description,omitempty"`
+	Tags        []string             `json:"tags,omitempty"`
+	Parameters  []Parameter          `json:"parameters,omitempty"`
+	RequestBody *RequestBody         `json:"requestBody,omitempty"`
+	Responses   map[string]*Response `json:"responses"`
+}
+
+// Parameter is a non-body input of an operation
+type Parameter struct {
+	Ref      string  `json:"$ref,omitempty"`
+	Name     string  `json:"name"`
+	In       string  `json:"in"`
+	Required bool    `json:"required,omitempty"`
+	Schema   *Schema `json:"schema"`
+}
+
+// RequestBody is the JSON body of an operation
+type RequestBody struct {
+	Required bool                 `json:"required"`
+	Content  map[string]MediaType `json:"content"`
+}
+
+// Response is a possible response of an operation
+type Response struct {
+	Description string               `json:"description"`
+	Content     map[string]MediaType `json:"content,omitempty"`
+}
+
+// MediaType describes the schema of a body
+type MediaType struct {
+	Schema *Schema `json:"schema"`
+}
+
+// Route describes the types used by a handler. These are the same types passed to
+// api.ReadJSON, api.QueryParam and api.Success.
+type Route struct {
+	OperationID string
+	Summary     string
+	Description string
+	Tags        []string
+	// Request is a value of the type of the JSON body
+	Request any
+	// Query is a value of the type of the query parameters
+	Query any
+	// Response is a value of the type of the successful response
+	Response any
+	// Status is the status code of a successful response. Defaults to 200
+	Status int
+}
+
+// Handler is an http.Handler annotated for documentation
+type Handler struct {
+	http.Handler
+	Route Route
+}
+
+// Describe annotates the handler with the route, for use with chi.Router.Method
+// or chi.Router.Handle.
+//
+//	router.Method(http.MethodPost, "/books", openapi.Describe(openapi.Route{
+//		Summary:  "Create a book",
+//		Request:  BookRequest{},
+//		Response: Book{},
+//		Status:   http.StatusCreated,
+//	}, http.HandlerFunc(createBook)))
+func Describe(route Route, h http.Handler) *Handler {
+	return &Handler{Handler: h, Route: route}
+}
+
+// Generate walks the routes of the router to create an OpenAPI document. Routes not
+// annotated with Describe are documented with just their path parameters. Schemas of
+// structs with ozzo validation reflect their Required, NilOrNotEmpty, Length, Min and
+// Max rules, but not In or Match.
+func Generate(router chi.Routes, info Info) (*Document, error) {
+	gen := newSchemas()
+	errSchema := gen.of(api.Err{})
+
+	doc := &Document{
+		OpenAPI: Version,
+		Info:    info,
+		Paths:   make(map[string]map[string]*Operation),
+	}
+
+	err := chi.Walk(router, func(method, pattern string, h http.Handler, _ ...func(http.Handler) http.Handler) error {
+		// wildcards are for static files, not APIs
+		if strings.Contains(pattern, "*") {
+			return nil
+		}
+
+		var route Route
+		if d, ok := h.(*Handler); ok {
+			route = d.Route
+		}
+
+		path := pathParam.ReplaceAllString(pattern, "{$1}")
+		op := &Operation{
+			OperationID: route.OperationID,
+			Summary:     route.Summary,
+			Description: route.Description,
+			Tags:        route.Tags,
+			Responses:   make(map[string]*Response),
+		}
+
+		for _, match := range pathParam.FindAllStringSubmatch(pattern, -1) {
+			op.Parameters = append(op.Parameters, Parameter{
+				Name:     match[1],
+				In:       "path",
+				Required: true,
+				Schema:   &Schema{Type: "string"},
+			})
+		}
+
+		if route.Query != nil {
+			op.Parameters = append(op.Parameters, queryParams(gen, route.Query)...)
+		}
+
+		if schema := gen.of(route.Request); schema != nil {
+			op.RequestBody = &RequestBody{
+				Required: true,
+				Content:  map[string]MediaType{"application/json": {Schema: schema}},
+			}
+		}
+
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		res := &Response{Description: http.StatusText(status)}
+		if schema := gen.of(route.Response); schema != nil {
+			res.Content = map[string]MediaType{"application/json": {Schema: schema}}
+		}
+		op.Responses[strconv.Itoa(status)] = res
+		op.Responses["default"] = &Response{
+			Description: "Error",
+			Content:     map[string]MediaType{"application/json": {Schema: errSchema}},
+		}
+
+		if doc.Paths[path] == nil {
+			doc.Paths[path] = make(map[string]*Operation)
+		}
+		doc.Paths[path][strings.ToLower(method)] = op
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	doc.Components.Schemas = gen.components
+
+	return doc, nil
+}
+
+// Serve adds a route for /openapi.json to the router. The document is generated the
+// first time it's requested so it includes routes added after Serve.
+func Serve(router chi.Router, info Info) {
+	var once sync.Once
+	var raw []byte
+	var err error
+
+	router.Get("/openapi.json", func(w http.ResponseWriter, _ *http.Request) {
+		once.Do(func() {
+			var doc *Document
+			if doc, err = Generate(router, info); err != nil {
+				return
+			}
+			raw, err = json.Marshal(doc)
+		})
+
+		if err != nil {
+			panic(err)
+		}
+
+		responses.Send(w, http.StatusOK, raw)
+	})
+}
+
+// queryParams converts the fields of a struct to query parameters
+func queryParams(gen *schemas, v any) []Parameter {
+	t := reflect.TypeOf(v)
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	if t.Kind() != reflect.Struct {
+		return nil
+	}
+
+	// query structs are flattened into parameters, so no need to save them
+	schema := gen.object(t)
+
+	required := make(map[string]bool)
+	for _, name := range schema.Required {
+		required[name] = true
+	}
+
+	var params []Parameter
+	for name, prop := range schema.Properties {
+		params = append(params, Parameter{
+			Name:     name,
+			In:       "query",
+			Required: required[name],
+			Schema:   prop,
+		})
+	}
+
+	sort.Slice(params, func(i, j int) bool {
+		return params[i].Name < params[j].Name
+	})
+
+	return params
+}
@@ -0,0 +1,174 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	ozzo "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/noxecane/anansi/json"
+)
+
+type author struct {
+	Name  string  `json:"name"`
+	Books []*book `json:"books"`
+}
+
+type book struct {
+	Title     string    `json:"title"`
+	Pages     int       `json:"pages,omitempty"`
+	Author    *author   `json:"author"`
+	CreatedAt time.Time `json:"created_at"`
+	secret    string
+}
+
+func (b *book) Validate() error {
+	return ozzo.ValidateStruct(b,
+		ozzo.Field(&b.Title, ozzo.Required, ozzo.Length(2, 200)),
+		ozzo.Field(&b.Pages, ozzo.Min(1), ozzo.Max(5000)),
+	)
+}
+
+type bookQuery struct {
+	Search string `json:"search"`
+	Limit  int    `json:"limit"`
+}
+
+func (q *bookQuery) Validate() error {
+	return ozzo.ValidateStruct(q,
+		ozzo.Field(&q.Search, ozzo.Required),
+	)
+}
+
+func newRouter() *chi.Mux {
+	noop := http.HandlerFunc(func(_ http.ResponseWriter, _ *http.Request) {})
+
+	router := chi.NewRouter()
+	router.Method(http.MethodPost, "/books", Describe(Route{
+		Summary:  "Create a book",
+		Request:  book{},
+		Response: book{},
+		Status:   http.StatusCreated,
+	}, noop))
+	router.Method(http.MethodGet, "/books", Describe(Route{
+		Query:    bookQuery{},
+		Response: []book{},
+	}, noop))
+	router.Get("/books/{id:[0-9]+}", noop)
+	router.Get("/static/*", noop)
+
+	return router
+}
+
+func TestGenerate(t *testing.T) {
+	doc, err := Generate(newRouter(), Info{Title: "Books", Version: "1.0.0"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	t.Run("documents request and response types", func(t *testing.T) {
+		op := doc.Paths["/books"]["post"]
+		if op == nil {
+			t.Fatal("Expected POST /books to be documented")
+		}
+
+		if op.RequestBody == nil || op.RequestBody.Content["application/json"].Schema.Ref != "#/components/schemas/book" {
+			t.Error("Expected request body to reference the book schema")
+		}
+
+		if op.Responses["201"] == nil {
+			t.Error("Expected a 201 response to be documented")
+		}
+
+		if op.Responses["default"] == nil {
+			t.Error("Expected the error response to be documented")
+		}
+	})
+
+	t.Run("reflects struct fields and ozzo rules", func(t *testing.T) {
+		schema := doc.Components.Schemas["book"]
+		if schema == nil {
+			t.Fatal("Expected book to be saved as a component")
+		}
+
+		if len(schema.Properties) != 4 {
+			t.Errorf("Expected book to have 4 properties, got %d", len(schema.Properties))
+		}
+
+		if schema.Properties["created_at"].Format != "date-time" {
+			t.Errorf("Expected created_at to be a date-time, got %s", schema.Properties["created_at"].Format)
+		}
+
+		if len(schema.Required) != 1 || schema.Required[0] != "title" {
+			t.Errorf("Expected only title to be required, got %v", schema.Required)
+		}
+
+		if doc.Components.Schemas["author"] == nil {
+			t.Error("Expected nested author to be saved as a component")
+		}
+
+		title := schema.Properties["title"]
+		if title.MinLength == nil || *title.MinLength != 2 || title.MaxLength == nil || *title.MaxLength != 200 {
+			t.Errorf("Expected title to be between 2 and 200 characters, got %v and %v", title.MinLength, title.MaxLength)
+		}
+
+		pages := schema.Properties["pages"]
+		if pages.Minimum == nil || *pages.Minimum != 1 || pages.Maximum == nil || *pages.Maximum != 5000 {
+			t.Errorf("Expected pages to be between 1 and 5000, got %v and %v", pages.Minimum, pages.Maximum)
+		}
+	})
+
+	t.Run("documents query and path parameters", func(t *testing.T) {
+		params := doc.Paths["/books"]["get"].Parameters
+		if len(params) != 2 {
+			t.Fatalf("Expected 2 query parameters, got %d", len(params))
+		}
+
+		if params[1].Name != "search" || !params[1].Required {
+			t.Errorf("Expected search to be a required query parameter, got %v", params[1])
+		}
+
+		op := doc.Paths["/books/{id}"]["get"]
+		if op == nil {
+			t.Fatal("Expected GET /books/{id} to be documented")
+		}
+
+		if len(op.Parameters) != 1 || op.Parameters[0].In != "path" {
+			t.Errorf("Expected id to be documented as a path parameter, got %v", op.Parameters)
+		}
+	})
+
+	t.Run("skips wildcard routes", func(t *testing.T) {
+		if _, ok := doc.Paths["/static/*"]; ok {
+			t.Error("Expected wildcard routes to be skipped")
+		}
+	})
+}
+
+func TestServe(t *testing.T) {
+	router := newRouter()
+	Serve(router, Info{Title: "Books", Version: "1.0.0"})
+
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/openapi.json", nil)
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK {
+		t.Fatalf("Expected the status code to be %d, got %d", http.StatusOK, res.Code)
+	}
+
+	var doc Document
+	if err := json.Unmarshal(res.Body.Bytes(), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	if doc.OpenAPI != Version {
+		t.Errorf("Expected openapi version to be %s, got %s", Version, doc.OpenAPI)
+	}
+
+	if _, ok := doc.Paths["/books"]; !ok {
+		t.Error("Expected /books to be documented")
+	}
+}
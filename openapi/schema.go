@@ -0,0 +1,421 @@
+package openapi
+
+import (
+	"bytes"
+	jsonslow "encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Schema is the subset of JSON schema supported by OpenAPI 3.0
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+	AllOf                []*Schema          `json:"allOf,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+	Pattern              string             `json:"pattern,omitempty"`
+	MinLength            *int               `json:"minLength,omitempty"`
+	MaxLength            *int               `json:"maxLength,omitempty"`
+	MinItems             *int               `json:"minItems,omitempty"`
+	MaxItems             *int               `json:"maxItems,omitempty"`
+	Minimum              *float64           `json:"minimum,omitempty"`
+	Maximum              *float64           `json:"maximum,omitempty"`
+	ExclusiveMinimum     bool               `json:"exclusiveMinimum,omitempty"`
+	ExclusiveMaximum     bool               `json:"exclusiveMaximum,omitempty"`
+
+	// NoAdditionalProperties is set for additionalProperties: false, which rejects
+	// properties that aren't in Properties.
+	NoAdditionalProperties bool `json:"-"`
+}
+
+// MarshalJSON writes NoAdditionalProperties as additionalProperties: false
+func (s Schema) MarshalJSON() ([]byte, error) {
+	type plain Schema
+	if !s.NoAdditionalProperties {
+		return jsonslow.Marshal(plain(s))
+	}
+
+	return jsonslow.Marshal(struct {
+		plain
+		AdditionalProperties bool `json:"additionalProperties"`
+	}{plain: plain(s)})
+}
+
+// UnmarshalJSON supports boolean additionalProperties, treating true as any value
+// and false as NoAdditionalProperties.
+func (s *Schema) UnmarshalJSON(b []byte) error {
+	type plain Schema
+	var raw struct {
+		*plain
+		AdditionalProperties jsonslow.RawMessage `json:"additionalProperties,omitempty"`
+	}
+	raw.plain = (*plain)(s)
+
+	if err := jsonslow.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	switch string(bytes.TrimSpace(raw.AdditionalProperties)) {
+	case "", "null":
+		s.AdditionalProperties = nil
+	case "false":
+		s.AdditionalProperties = nil
+		s.NoAdditionalProperties = true
+	case "true":
+		s.AdditionalProperties = &Schema{}
+	default:
+		s.AdditionalProperties = new(Schema)
+		return jsonslow.Unmarshal(raw.AdditionalProperties, s.AdditionalProperties)
+	}
+
+	return nil
+}
+
+var (
+	timeType    = reflect.TypeOf(time.Time{})
+	invalidName = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+)
+
+// schemas generates JSON schemas from go types, saving named structs as
+// components.
+type schemas struct {
+	components map[string]*Schema
+	names      map[reflect.Type]string
+}
+
+func newSchemas() *schemas {
+	return &schemas{
+		components: make(map[string]*Schema),
+		names:      make(map[reflect.Type]string),
+	}
+}
+
+// of returns the schema for the type of v, nil if v is nil.
+func (s *schemas) of(v any) *Schema {
+	if v == nil {
+		return nil
+	}
+
+	return s.forType(reflect.TypeOf(v))
+}
+
+func (s *schemas) forType(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		schema := s.forType(t.Elem())
+		if schema.Ref == "" {
+			schema.Nullable = true
+		}
+		return schema
+	}
+
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		// encoding/json sends byte slices as base64
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: s.forType(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: s.forType(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return s.object(t)
+		}
+		return s.ref(t)
+	default:
+		// interfaces, funcs e.t.c. could be anything
+		return &Schema{}
+	}
+}
+
+// ref saves the schema of a named struct as a component and returns a reference
+// to it.
+func (s *schemas) ref(t reflect.Type) *Schema {
+	name, ok := s.names[t]
+	if !ok {
+		name = invalidName.ReplaceAllString(t.Name(), "_")
+		if _, taken := s.components[name]; taken {
+			name = invalidName.ReplaceAllString(t.PkgPath()+"."+t.Name(), "_")
+		}
+
+		// save the name first to support recursive types
+		s.names[t] = name
+		s.components[name] = &Schema{}
+		*s.components[name] = *s.object(t)
+	}
+
+	return &Schema{Ref: "#/components/schemas/" + name}
+}
+
+func (s *schemas) object(t reflect.Type) *Schema {
+	schema := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+	s.fields(schema, t)
+	schema.Required = required(t)
+	constrain(t, schema.Properties)
+
+	return schema
+}
+
+func (s *schemas) fields(schema *Schema, t reflect.Type) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := fieldName(f)
+		if !ok {
+			continue
+		}
+
+		// flatten embedded structs the way encoding/json does
+		if f.Anonymous && name == "" {
+			ft := f.Type
+			if ft.Kind() == reflect.Pointer {
+				ft = ft.Elem()
+			}
+
+			if ft.Kind() == reflect.Struct {
+				s.fields(schema, ft)
+				continue
+			}
+
+			name = f.Name
+		}
+
+		schema.Properties[name] = s.forType(f.Type)
+	}
+}
+
+// fieldName gets the JSON name of a struct field. The name is empty for embedded
+// fields without a JSON name, and it's not ok if the field is not serialised.
+func fieldName(f reflect.StructField) (string, bool) {
+	if !f.IsExported() && !f.Anonymous {
+		return "", false
+	}
+
+	tag := f.Tag.Get("json")
+	if tag == "-" {
+		return "", false
+	}
+
+	name, _, _ := strings.Cut(tag, ",")
+	if name == "" && !f.Anonymous {
+		name = f.Name
+	}
+
+	return name, true
+}
+
+// longProbe is the length of the strings and slices used to find the maximum
+// length of fields. Larger maximums are left out of schemas.
+const longProbe = 1 << 12
+
+// constrain reflects ozzo's Length, Min and Max rules on the fields of a struct into
+// their schemas. Those rules skip empty values, so each field is probed with values
+// that break them instead: a single item and a very long value for Length, and the
+// smallest and largest numbers of its type for Min and Max. In and Match rules can't
+// be reflected, since their errors don't say which values are allowed.
+func constrain(t reflect.Type, props map[string]*Schema) {
+	if _, ok := reflect.New(t).Interface().(validation.Validatable); !ok {
+		return
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, ok := fieldName(f)
+		if !ok || f.Anonymous || props[name] == nil {
+			continue
+		}
+
+		for _, v := range probes(f.Type) {
+			if verr := probe(t, i, name, v); verr != nil {
+				applyRule(props[name], verr)
+			}
+		}
+	}
+}
+
+// probes creates values of t(or what it points to) that break Length, Min and Max rules
+func probes(t reflect.Type) []reflect.Value {
+	if t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	short, long := reflect.New(t).Elem(), reflect.New(t).Elem()
+
+	switch t.Kind() {
+	case reflect.String:
+		short.SetString("x")
+		long.SetString(strings.Repeat("x", longProbe))
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return nil
+		}
+		short.Set(reflect.MakeSlice(t, 1, 1))
+		long.Set(reflect.MakeSlice(t, longProbe, longProbe))
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		short.SetInt(-1 << (t.Bits() - 1))
+		long.SetInt(1<<(t.Bits()-1) - 1)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		short.SetUint(1)
+		long.SetUint(math.MaxUint64 >> (64 - t.Bits()))
+	case reflect.Float32:
+		short.SetFloat(-math.MaxFloat32)
+		long.SetFloat(math.MaxFloat32)
+	case reflect.Float64:
+		short.SetFloat(-math.MaxFloat64)
+		long.SetFloat(math.MaxFloat64)
+	default:
+		return nil
+	}
+
+	return []reflect.Value{short, long}
+}
+
+// probe validates a struct whose ith field is set to v, returning the error of the field
+func probe(t reflect.Type, i int, name string, v reflect.Value) (verr validation.Error) {
+	// validation rules are free to assume other fields are not empty
+	defer func() {
+		if recover() != nil {
+			verr = nil
+		}
+	}()
+
+	ptr := reflect.New(t)
+	field := ptr.Elem().Field(i)
+	if field.Kind() == reflect.Pointer {
+		field.Set(reflect.New(field.Type().Elem()))
+		field.Elem().Set(v)
+	} else {
+		field.Set(v)
+	}
+
+	var errs validation.Errors
+	if !errors.As(ptr.Interface().(validation.Validatable).Validate(), &errs) {
+		return nil
+	}
+
+	if errors.As(errs[name], &verr) {
+		return verr
+	}
+
+	return nil
+}
+
+// applyRule sets the constraint described by a Length, Min or Max error on the schema
+func applyRule(schema *Schema, verr validation.Error) {
+	params := verr.Params()
+
+	switch verr.Code() {
+	case validation.ErrLengthTooShort.Code(), validation.ErrLengthTooLong.Code(),
+		validation.ErrLengthInvalid.Code(), validation.ErrLengthOutOfRange.Code():
+		minLen, hasMin := params["min"].(int)
+		maxLen, hasMax := params["max"].(int)
+		hasMin = hasMin && minLen > 0
+		hasMax = hasMax && maxLen > 0
+
+		if schema.Type == "array" {
+			if hasMin {
+				schema.MinItems = &minLen
+			}
+			if hasMax {
+				schema.MaxItems = &maxLen
+			}
+		} else {
+			if hasMin {
+				schema.MinLength = &minLen
+			}
+			if hasMax {
+				schema.MaxLength = &maxLen
+			}
+		}
+	case validation.ErrMinGreaterEqualThanRequired.Code(), validation.ErrMinGreaterThanRequired.Code():
+		if threshold, ok := toFloat(params["threshold"]); ok {
+			schema.Minimum = &threshold
+			schema.ExclusiveMinimum = verr.Code() == validation.ErrMinGreaterThanRequired.Code()
+		}
+	case validation.ErrMaxLessEqualThanRequired.Code(), validation.ErrMaxLessThanRequired.Code():
+		if threshold, ok := toFloat(params["threshold"]); ok {
+			schema.Maximum = &threshold
+			schema.ExclusiveMaximum = verr.Code() == validation.ErrMaxLessThanRequired.Code()
+		}
+	}
+}
+
+func toFloat(v any) (float64, bool) {
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	default:
+		return 0, false
+	}
+}
+
+// required finds the fields of a struct that fail ozzo's required rules when
+// they're not set, by validating the zero value of the struct.
+func required(t reflect.Type) (fields []string) {
+	v, ok := reflect.New(t).Interface().(validation.Validatable)
+	if !ok {
+		return nil
+	}
+
+	// validation rules are free to assume the value is not empty
+	defer func() {
+		if recover() != nil {
+			fields = nil
+		}
+	}()
+
+	var errs validation.Errors
+	if !errors.As(v.Validate(), &errs) {
+		return nil
+	}
+
+	for name, err := range errs {
+		var verr validation.Error
+		if !errors.As(err, &verr) {
+			continue
+		}
+
+		switch verr.Code() {
+		case validation.ErrRequired.Code(), validation.ErrNilOrNotEmpty.Code():
+			fields = append(fields, name)
+		}
+	}
+
+	sort.Strings(fields)
+
+	return fields
+}
@@ -0,0 +1,501 @@
+package openapi
+
+import (
+	jsonslow "encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"net/url"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+
+	validation "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/noxecane/anansi/api"
+	"github.com/noxecane/anansi/requests"
+)
+
+// ErrTypeInvalid is the validation error for values of the wrong type
+var ErrTypeInvalid = validation.NewError("validation_type_invalid", "must be of type {{.type}}")
+
+// ErrPropertyUnknown is the validation error for properties of objects with
+// additionalProperties: false that aren't in the schema
+var ErrPropertyUnknown = validation.NewError("validation_property_unknown", "is not allowed")
+
+var uuidFormat = regexp.MustCompile(`^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`)
+
+type operation struct {
+	method  string
+	pattern *regexp.Regexp
+	names   []string
+	op      *Operation
+}
+
+type validator struct {
+	doc        *Document
+	operations []operation
+	patterns   sync.Map
+}
+
+// Validate creates a middleware that validates the path params, query, headers and
+// JSON body of requests against the operations of the document before calling the
+// handler. Violations are sent as a 400 api.Err with the validation.Errors in its
+// data, the same way api.ReadJSON does. Requests for paths and methods not in the
+// document are left alone.
+func Validate(doc *Document) func(http.Handler) http.Handler {
+	v := &validator{doc: doc}
+
+	for path, ops := range doc.Paths {
+		pattern := "^" + regexp.QuoteMeta(path) + "$"
+		var names []string
+
+		for _, match := range pathParam.FindAllStringSubmatch(path, -1) {
+			names = append(names, match[1])
+			pattern = strings.Replace(pattern, regexp.QuoteMeta(match[0]), "([^/]+)", 1)
+		}
+
+		re := regexp.MustCompile(pattern)
+		for method, op := range ops {
+			v.operations = append(v.operations, operation{
+				method:  strings.ToUpper(method),
+				pattern: re,
+				names:   names,
+				op:      op,
+			})
+		}
+	}
+
+	// prefer static paths to those with params
+	sort.SliceStable(v.operations, func(i, j int) bool {
+		return len(v.operations[i].names) < len(v.operations[j].names)
+	})
+
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := v.request(r); err != nil {
+				api.Error(r, w, *err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+func (v *validator) request(r *http.Request) *api.Err {
+	op, pathValues := v.match(r)
+	if op == nil {
+		return nil
+	}
+
+	errs := validation.Errors{}
+	query := r.URL.Query()
+
+	for _, p := range op.Parameters {
+		param := v.param(p)
+		if param == nil {
+			continue
+		}
+
+		var values []string
+		switch param.In {
+		case "path":
+			if value, ok := pathValues[param.Name]; ok {
+				values = []string{value}
+			}
+		case "query":
+			values = query[param.Name]
+		case "header":
+			values = r.Header.Values(param.Name)
+		default:
+			continue
+		}
+
+		if len(values) == 0 {
+			if param.Required || param.In == "path" {
+				errs[param.Name] = validation.ErrRequired
+			}
+			continue
+		}
+
+		schema := v.resolve(param.Schema)
+		if err := v.check(schema, v.parse(schema, values)); err != nil {
+			errs[param.Name] = err
+		}
+	}
+
+	if op.RequestBody != nil {
+		if err := v.body(r, op.RequestBody, errs); err != nil {
+			return err
+		}
+	}
+
+	if len(errs) > 0 {
+		return &api.Err{
+			Code:    http.StatusBadRequest,
+			Message: "We could not validate your request.",
+			Data:    errs,
+		}
+	}
+
+	return nil
+}
+
+func (v *validator) match(r *http.Request) (*Operation, map[string]string) {
+	for _, o := range v.operations {
+		if o.method != r.Method {
+			continue
+		}
+
+		matches := o.pattern.FindStringSubmatch(r.URL.Path)
+		if matches == nil {
+			continue
+		}
+
+		values := make(map[string]string)
+		for i, name := range o.names {
+			value, err := url.PathUnescape(matches[i+1])
+			if err != nil {
+				value = matches[i+1]
+			}
+			values[name] = value
+		}
+
+		return o.op, values
+	}
+
+	return nil, nil
+}
+
+func (v *validator) body(r *http.Request, body *RequestBody, errs validation.Errors) *api.Err {
+	requests.LimitBody(r)
+	raw, err := requests.ReadBody(r)
+
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return &api.Err{
+			Code:    http.StatusRequestEntityTooLarge,
+			Message: fmt.Sprintf("Your request body cannot be larger than %d bytes.", tooLarge.Limit),
+			Err:     err,
+		}
+	} else if err != nil {
+		return &api.Err{
+			Code:    http.StatusBadRequest,
+			Message: "We cannot parse your request body.",
+			Err:     err,
+		}
+	}
+
+	if len(raw) == 0 {
+		if body.Required {
+			errs["body"] = validation.ErrRequired
+		}
+		return nil
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	content, ok := body.Content[mediaType]
+	if !ok {
+		return &api.Err{
+			Code:    http.StatusUnsupportedMediaType,
+			Message: http.StatusText(http.StatusUnsupportedMediaType),
+		}
+	}
+
+	if content.Schema == nil || !(mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")) {
+		return nil
+	}
+
+	var value any
+	if err := jsonslow.Unmarshal(raw, &value); err != nil {
+		return &api.Err{
+			Code:    http.StatusBadRequest,
+			Message: "We cannot parse your request body.",
+			Err:     err,
+		}
+	}
+
+	switch err := v.check(v.resolve(content.Schema), value).(type) {
+	case nil:
+	case validation.Errors:
+		// report fields the same way ReadJSON would
+		for k, e := range err {
+			errs[k] = e
+		}
+	default:
+		errs["body"] = err
+	}
+
+	return nil
+}
+
+// param resolves references to shared parameters
+func (v *validator) param(p Parameter) *Parameter {
+	if p.Ref == "" {
+		return &p
+	}
+
+	return v.doc.Components.Parameters[strings.TrimPrefix(p.Ref, "#/components/parameters/")]
+}
+
+// resolve follows references to shared schemas
+func (v *validator) resolve(s *Schema) *Schema {
+	for s != nil && s.Ref != "" {
+		s = v.doc.Components.Schemas[strings.TrimPrefix(s.Ref, "#/components/schemas/")]
+	}
+
+	return s
+}
+
+// check validates value against the schema, returning validation.Errors for
+// objects and arrays.
+func (v *validator) check(s *Schema, value any) error {
+	s = v.resolve(s)
+	if s == nil {
+		return nil
+	}
+
+	if value == nil {
+		if s.Nullable || s.Type == "" {
+			return nil
+		}
+		return validation.ErrRequired
+	}
+
+	for _, sub := range s.AllOf {
+		if err := v.check(sub, value); err != nil {
+			return err
+		}
+	}
+
+	var err error
+	switch s.Type {
+	case "object":
+		err = v.object(s, value)
+	case "array":
+		err = v.array(s, value)
+	case "string":
+		err = v.string(s, value)
+	case "integer":
+		if n, ok := value.(float64); !ok || n != float64(int64(n)) {
+			return ErrTypeInvalid.SetParams(map[string]interface{}{"type": s.Type})
+		}
+		err = checkNumber(s, value.(float64))
+	case "number":
+		n, ok := value.(float64)
+		if !ok {
+			return ErrTypeInvalid.SetParams(map[string]interface{}{"type": s.Type})
+		}
+		err = checkNumber(s, n)
+	case "boolean":
+		if _, ok := value.(bool); !ok {
+			return ErrTypeInvalid.SetParams(map[string]interface{}{"type": s.Type})
+		}
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if len(s.Enum) > 0 {
+		for _, e := range s.Enum {
+			if reflect.DeepEqual(e, value) {
+				return nil
+			}
+		}
+		return validation.ErrInInvalid
+	}
+
+	return nil
+}
+
+func (v *validator) object(s *Schema, value any) error {
+	obj, ok := value.(map[string]any)
+	if !ok {
+		return ErrTypeInvalid.SetParams(map[string]interface{}{"type": s.Type})
+	}
+
+	errs := validation.Errors{}
+	for _, name := range s.Required {
+		if _, ok := obj[name]; !ok {
+			errs[name] = validation.ErrRequired
+		}
+	}
+
+	for name, field := range obj {
+		prop, ok := s.Properties[name]
+		if !ok && s.NoAdditionalProperties {
+			errs[name] = ErrPropertyUnknown
+			continue
+		} else if !ok {
+			prop = s.AdditionalProperties
+		}
+
+		if err := v.check(prop, field); err != nil {
+			errs[name] = err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (v *validator) array(s *Schema, value any) error {
+	items, ok := value.([]any)
+	if !ok {
+		return ErrTypeInvalid.SetParams(map[string]interface{}{"type": s.Type})
+	}
+
+	if err := checkLength(s.MinItems, s.MaxItems, len(items)); err != nil {
+		return err
+	}
+
+	errs := validation.Errors{}
+	for i, item := range items {
+		if err := v.check(s.Items, item); err != nil {
+			errs[strconv.Itoa(i)] = err
+		}
+	}
+
+	if len(errs) > 0 {
+		return errs
+	}
+
+	return nil
+}
+
+func (v *validator) string(s *Schema, value any) error {
+	str, ok := value.(string)
+	if !ok {
+		return ErrTypeInvalid.SetParams(map[string]interface{}{"type": s.Type})
+	}
+
+	if err := checkLength(s.MinLength, s.MaxLength, utf8.RuneCountInString(str)); err != nil {
+		return err
+	}
+
+	if s.Pattern != "" {
+		re, err := v.pattern(s.Pattern)
+		if err == nil && !re.MatchString(str) {
+			return validation.ErrMatchInvalid
+		}
+	}
+
+	switch s.Format {
+	case "date-time":
+		if _, err := time.Parse(time.RFC3339, str); err != nil {
+			return validation.ErrDateInvalid
+		}
+	case "date":
+		if _, err := time.Parse("2006-01-02", str); err != nil {
+			return validation.ErrDateInvalid
+		}
+	case "uuid":
+		if !uuidFormat.MatchString(str) {
+			return validation.ErrMatchInvalid
+		}
+	}
+
+	return nil
+}
+
+// pattern compiles the regular expression once for all requests
+func (v *validator) pattern(expr string) (*regexp.Regexp, error) {
+	if re, ok := v.patterns.Load(expr); ok {
+		return re.(*regexp.Regexp), nil
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, err
+	}
+	v.patterns.Store(expr, re)
+
+	return re, nil
+}
+
+func checkLength(min, max *int, length int) error {
+	switch {
+	case min != nil && max != nil && (length < *min || length > *max):
+		if *min == *max {
+			return validation.ErrLengthInvalid.SetParams(map[string]interface{}{"min": *min})
+		}
+		return validation.ErrLengthOutOfRange.SetParams(map[string]interface{}{"min": *min, "max": *max})
+	case min != nil && length < *min:
+		return validation.ErrLengthTooShort.SetParams(map[string]interface{}{"min": *min})
+	case max != nil && length > *max:
+		return validation.ErrLengthTooLong.SetParams(map[string]interface{}{"max": *max})
+	default:
+		return nil
+	}
+}
+
+func checkNumber(s *Schema, n float64) error {
+	if s.Minimum != nil {
+		if s.ExclusiveMinimum && n <= *s.Minimum {
+			return validation.ErrMinGreaterThanRequired.SetParams(map[string]interface{}{"threshold": *s.Minimum})
+		}
+
+		if n < *s.Minimum {
+			return validation.ErrMinGreaterEqualThanRequired.SetParams(map[string]interface{}{"threshold": *s.Minimum})
+		}
+	}
+
+	if s.Maximum != nil {
+		if s.ExclusiveMaximum && n >= *s.Maximum {
+			return validation.ErrMaxLessThanRequired.SetParams(map[string]interface{}{"threshold": *s.Maximum})
+		}
+
+		if n > *s.Maximum {
+			return validation.ErrMaxLessEqualThanRequired.SetParams(map[string]interface{}{"threshold": *s.Maximum})
+		}
+	}
+
+	return nil
+}
+
+// parse converts the raw values of a parameter to the type of its schema. Values
+// that can't be converted are left as strings for check to reject.
+func (v *validator) parse(s *Schema, values []string) any {
+	if s == nil {
+		return values[0]
+	}
+
+	if s.Type == "array" {
+		// support both ?id=1&id=2 and ?id=1,2
+		if len(values) == 1 {
+			values = strings.Split(values[0], ",")
+		}
+
+		items := v.resolve(s.Items)
+		parsed := make([]any, len(values))
+		for i, value := range values {
+			parsed[i] = v.parse(items, []string{value})
+		}
+
+		return parsed
+	}
+
+	raw := values[0]
+	switch s.Type {
+	case "integer", "number":
+		if n, err := strconv.ParseFloat(raw, 64); err == nil {
+			return n
+		}
+	case "boolean":
+		if b, err := strconv.ParseBool(raw); err == nil {
+			return b
+		}
+	}
+
+	return raw
+}
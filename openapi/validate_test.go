@@ -0,0 +1,231 @@
+package openapi
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/noxecane/anansi/json"
+	"github.com/noxecane/anansi/requests"
+)
+
+const spec = `
+openapi: 3.0.3
+info:
+  title: Books
+  version: 1.0.0
+paths:
+  /books:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            minimum: 1
+            maximum: 100
+        - $ref: '#/components/parameters/Tenant'
+      responses:
+        '200':
+          description: OK
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Book'
+      responses:
+        '201':
+          description: Created
+  /books/{id}:
+    parameters:
+      - name: id
+        in: path
+        required: true
+        schema:
+          type: integer
+    get:
+      responses:
+        '200':
+          description: OK
+components:
+  parameters:
+    Tenant:
+      name: X-Tenant
+      in: header
+      required: true
+      schema:
+        type: string
+  schemas:
+    Book:
+      type: object
+      required: [title]
+      additionalProperties: false
+      properties:
+        title:
+          type: string
+          minLength: 2
+        genre:
+          type: string
+          enum: [fiction, history]
+`
+
+type validationErr struct {
+	Message string            `json:"message"`
+	Data    map[string]string `json:"data"`
+}
+
+func TestValidate(t *testing.T) {
+	doc, err := Load(strings.NewReader(spec))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	router := chi.NewRouter()
+	router.Use(Validate(doc))
+	router.Get("/books", func(w http.ResponseWriter, _ *http.Request) {})
+	router.Post("/books", func(w http.ResponseWriter, _ *http.Request) {})
+	router.Get("/books/{id}", func(w http.ResponseWriter, _ *http.Request) {})
+	router.Get("/authors", func(w http.ResponseWriter, _ *http.Request) {})
+
+	send := func(req *http.Request) (*httptest.ResponseRecorder, validationErr) {
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		var body validationErr
+		if res.Code != http.StatusOK {
+			_ = json.Unmarshal(res.Body.Bytes(), &body)
+		}
+
+		return res, body
+	}
+
+	t.Run("allows valid requests", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/books?limit=20", nil)
+		req.Header.Set("X-Tenant", "acme")
+
+		if res, _ := send(req); res.Code != http.StatusOK {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusOK, res.Code)
+		}
+
+		req = httptest.NewRequest("POST", "/books", strings.NewReader(`{"title": "Arrow of God", "genre": "fiction"}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		if res, _ := send(req); res.Code != http.StatusOK {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusOK, res.Code)
+		}
+	})
+
+	t.Run("rejects invalid query and headers", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/books?limit=500", nil)
+		res, body := send(req)
+
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("Expected the status code to be %d, got %d", http.StatusBadRequest, res.Code)
+		}
+
+		if body.Data["limit"] == "" {
+			t.Error("Expected limit to fail validation")
+		}
+
+		if body.Data["X-Tenant"] == "" {
+			t.Error("Expected missing X-Tenant header to fail validation")
+		}
+	})
+
+	t.Run("rejects invalid path params", func(t *testing.T) {
+		res, body := send(httptest.NewRequest("GET", "/books/arrow", nil))
+
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("Expected the status code to be %d, got %d", http.StatusBadRequest, res.Code)
+		}
+
+		if body.Data["id"] == "" {
+			t.Error("Expected id to fail validation")
+		}
+	})
+
+	t.Run("rejects invalid JSON bodies", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/books", strings.NewReader(`{"title": "A", "genre": "poetry"}`))
+		req.Header.Set("Content-Type", "application/json")
+		res, body := send(req)
+
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("Expected the status code to be %d, got %d", http.StatusBadRequest, res.Code)
+		}
+
+		if body.Data["title"] == "" || body.Data["genre"] == "" {
+			t.Errorf("Expected title and genre to fail validation, got %v", body.Data)
+		}
+	})
+
+	t.Run("rejects properties the schema doesn't allow", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/books", strings.NewReader(`{"title": "Arrow of God", "isbn": "9780385014809"}`))
+		req.Header.Set("Content-Type", "application/json")
+		res, body := send(req)
+
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("Expected the status code to be %d, got %d", http.StatusBadRequest, res.Code)
+		}
+
+		if body.Data["isbn"] == "" {
+			t.Errorf("Expected isbn to fail validation, got %v", body.Data)
+		}
+
+		raw, err := json.Marshal(doc.Components.Schemas["Book"])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !strings.Contains(string(raw), `"additionalProperties":false`) {
+			t.Errorf("Expected additionalProperties to be written as false, got %s", raw)
+		}
+	})
+
+	t.Run("rejects missing JSON bodies", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/books", nil)
+		req.Header.Set("Content-Type", "application/json")
+		res, body := send(req)
+
+		if res.Code != http.StatusBadRequest {
+			t.Fatalf("Expected the status code to be %d, got %d", http.StatusBadRequest, res.Code)
+		}
+
+		if body.Data["body"] == "" {
+			t.Error("Expected missing body to fail validation")
+		}
+	})
+
+	t.Run("rejects unsupported content types", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/books", strings.NewReader("title=Arrow"))
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		if res, _ := send(req); res.Code != http.StatusUnsupportedMediaType {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusUnsupportedMediaType, res.Code)
+		}
+	})
+
+	t.Run("ignores undocumented routes", func(t *testing.T) {
+		if res, _ := send(httptest.NewRequest("GET", "/authors", nil)); res.Code != http.StatusOK {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusOK, res.Code)
+		}
+	})
+	t.Run("rejects bodies over the decoding limit", func(t *testing.T) {
+		limited := chi.NewRouter()
+		limited.Use(requests.Decoding(requests.DecodeOptions{MaxBytes: 16}))
+		limited.Use(Validate(doc))
+		limited.Post("/books", func(w http.ResponseWriter, _ *http.Request) {})
+
+		req := httptest.NewRequest("POST", "/books", strings.NewReader(`{"title": "Arrow of God", "genre": "fiction"}`))
+		req.Header.Set("Content-Type", "application/json")
+		res := httptest.NewRecorder()
+		limited.ServeHTTP(res, req)
+
+		if res.Code != http.StatusRequestEntityTooLarge {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusRequestEntityTooLarge, res.Code)
+		}
+	})
+}
@@ -0,0 +1,95 @@
+package postgres
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/noxecane/anansi/requests"
+)
+
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+var comparisons = map[requests.Op]string{
+	requests.Eq:  "=",
+	requests.Ne:  "<>",
+	requests.Gt:  ">",
+	requests.Gte: ">=",
+	requests.Lt:  "<",
+	requests.Lte: "<=",
+}
+
+// Where renders the conditions as a parameterized WHERE clause, numbering the
+// placeholders from start(usually 1). It returns an empty clause when there are no
+// conditions.
+func Where(conds []requests.Condition, start int) (string, []any) {
+	if len(conds) == 0 {
+		return "", nil
+	}
+
+	var args []any
+	clauses := make([]string, 0, len(conds))
+	next := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", start+len(args)-1)
+	}
+
+	for _, c := range conds {
+		col := Identifier(c.Column)
+
+		switch c.Op {
+		case requests.In:
+			values, _ := c.Value.([]any)
+			placeholders := make([]string, len(values))
+			for i, v := range values {
+				placeholders[i] = next(v)
+			}
+			clauses = append(clauses, fmt.Sprintf("%s IN (%s)", col, strings.Join(placeholders, ", ")))
+		case requests.Contains:
+			pattern := "%" + likeEscaper.Replace(fmt.Sprint(c.Value)) + "%"
+			clauses = append(clauses, fmt.Sprintf("%s ILIKE %s", col, next(pattern)))
+		case requests.IsNull:
+			if isNull, _ := c.Value.(bool); isNull {
+				clauses = append(clauses, col+" IS NULL")
+			} else {
+				clauses = append(clauses, col+" IS NOT NULL")
+			}
+		default:
+			op, ok := comparisons[c.Op]
+			if !ok {
+				panic(fmt.Errorf("unsupported filter op %s", c.Op))
+			}
+			clauses = append(clauses, fmt.Sprintf("%s %s %s", col, op, next(c.Value)))
+		}
+	}
+
+	return "WHERE " + strings.Join(clauses, " AND "), args
+}
+
+// OrderBy renders the orders as an ORDER BY clause. It returns an empty clause
+// when there are no orders.
+func OrderBy(orders []requests.Order) string {
+	if len(orders) == 0 {
+		return ""
+	}
+
+	clauses := make([]string, len(orders))
+	for i, o := range orders {
+		dir := "ASC"
+		if o.Desc {
+			dir = "DESC"
+		}
+		clauses[i] = Identifier(o.Column) + " " + dir
+	}
+
+	return "ORDER BY " + strings.Join(clauses, ", ")
+}
+
+// Identifier quotes a(possibly table qualified) column name for use in SQL
+func Identifier(name string) string {
+	parts := strings.Split(name, ".")
+	for i, p := range parts {
+		parts[i] = `"` + strings.ReplaceAll(p, `"`, `""`) + `"`
+	}
+
+	return strings.Join(parts, ".")
+}
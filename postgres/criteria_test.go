@@ -0,0 +1,42 @@
+package postgres
+
+import (
+	"testing"
+
+	"github.com/noxecane/anansi/requests"
+)
+
+func TestWhere(t *testing.T) {
+	t.Run("renders parameterized conditions", func(t *testing.T) {
+		clause, args := Where([]requests.Condition{
+			{Column: "pages", Op: requests.Gte, Value: int64(100)},
+			{Column: "books.genre", Op: requests.In, Value: []any{"fiction", "history"}},
+			{Column: "title", Op: requests.Contains, Value: "50%"},
+			{Column: "deleted_at", Op: requests.IsNull, Value: true},
+		}, 2)
+
+		expected := `WHERE "pages" >= $2 AND "books"."genre" IN ($3, $4) AND "title" ILIKE $5 AND "deleted_at" IS NULL`
+		if clause != expected {
+			t.Errorf("Expected clause to be %s, got %s", expected, clause)
+		}
+
+		if len(args) != 4 || args[3] != `%50\%%` {
+			t.Errorf("Expected 4 args with an escaped pattern, got %v", args)
+		}
+	})
+
+	t.Run("renders nothing without conditions", func(t *testing.T) {
+		if clause, args := Where(nil, 1); clause != "" || args != nil {
+			t.Errorf("Expected an empty clause, got %s %v", clause, args)
+		}
+	})
+}
+
+func TestOrderBy(t *testing.T) {
+	clause := OrderBy([]requests.Order{{Column: "created_at", Desc: true}, {Column: `na"me`}})
+
+	expected := `ORDER BY "created_at" DESC, "na""me" ASC`
+	if clause != expected {
+		t.Errorf("Expected clause to be %s, got %s", expected, clause)
+	}
+}
@@ -0,0 +1,128 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/noxecane/anansi/internal/expiring"
+	"github.com/redis/go-redis/v9"
+)
+
+// tokenBucketScript refills the bucket for the time elapsed since the last request
+// and takes a token out if there's one.
+var tokenBucketScript = redis.NewScript(`
+local capacity = tonumber(ARGV[1])
+local rate = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+local state = redis.call("HMGET", KEYS[1], "tokens", "ts")
+local tokens = tonumber(state[1]) or capacity
+local ts = tonumber(state[2]) or now
+
+tokens = math.min(capacity, tokens + math.max(0, now - ts) * rate)
+
+local allowed = 0
+if tokens >= 1 then
+	tokens = tokens - 1
+	allowed = 1
+end
+
+redis.call("HSET", KEYS[1], "tokens", tostring(tokens), "ts", now)
+redis.call("PEXPIRE", KEYS[1], math.ceil((capacity - tokens) / rate) + 1000)
+
+return {allowed, tostring(tokens)}
+`)
+
+type redisTokenBucket struct {
+	redis *redis.Client
+	rate  Rate
+}
+
+// NewTokenBucket creates a token bucket Limiter that keeps each bucket in a redis hash,
+// refilled and drawn from by a script so instances never race on it. Buckets hold up to
+// rate.Limit tokens and are refilled steadily over rate.Period, allowing short bursts.
+func NewTokenBucket(r *redis.Client, rate Rate) Limiter {
+	return &redisTokenBucket{redis: r, rate: rate}
+}
+
+func (tb *redisTokenBucket) Allow(ctx context.Context, key string) (Result, error) {
+	perMs := float64(tb.rate.Limit) / float64(tb.rate.Period.Milliseconds())
+
+	res, err := tokenBucketScript.Run(ctx, tb.redis, []string{Prefix + "bucket:" + key},
+		tb.rate.Limit, strconv.FormatFloat(perMs, 'f', -1, 64), time.Now().UnixMilli(),
+	).Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	tokens, err := strconv.ParseFloat(res[1].(string), 64)
+	if err != nil {
+		return Result{}, err
+	}
+
+	return bucketResult(tb.rate, res[0].(int64) == 1, tokens), nil
+}
+
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+type memoryTokenBucket struct {
+	mu      sync.Mutex
+	rate    Rate
+	buckets *expiring.Map[bucket]
+}
+
+// NewMemoryTokenBucket creates a token bucket Limiter that keeps its buckets in memory.
+// Every instance has its own buckets, so each allows clients the full rate.
+func NewMemoryTokenBucket(rate Rate) Limiter {
+	return &memoryTokenBucket{rate: rate, buckets: expiring.New[bucket](rate.Period)}
+}
+
+func (tb *memoryTokenBucket) Allow(_ context.Context, key string) (Result, error) {
+	tb.mu.Lock()
+	defer tb.mu.Unlock()
+
+	now := time.Now()
+	capacity := float64(tb.rate.Limit)
+	perNs := capacity / float64(tb.rate.Period)
+
+	b, ok := tb.buckets.Get(key, now)
+	if !ok {
+		b = bucket{tokens: capacity, last: now}
+	}
+
+	b.tokens = math.Min(capacity, b.tokens+float64(now.Sub(b.last))*perNs)
+	b.last = now
+
+	allowed := b.tokens >= 1
+	if allowed {
+		b.tokens--
+	}
+
+	// buckets left alone for a period are full, same as new ones
+	tb.buckets.Set(key, b, now, tb.rate.Period)
+
+	return bucketResult(tb.rate, allowed, b.tokens), nil
+}
+
+func bucketResult(rate Rate, allowed bool, tokens float64) Result {
+	perNs := float64(rate.Limit) / float64(rate.Period)
+
+	res := Result{
+		Allowed:   allowed,
+		Limit:     rate.Limit,
+		Remaining: int(math.Floor(tokens)),
+		Reset:     time.Duration((float64(rate.Limit) - tokens) / perNs),
+	}
+
+	if !allowed {
+		res.RetryAfter = time.Duration((1 - tokens) / perNs)
+	}
+
+	return res
+}
@@ -0,0 +1,129 @@
+package ratelimit
+
+import (
+	"context"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/noxecane/anansi/api"
+	"github.com/noxecane/anansi/sessions"
+	"github.com/rs/zerolog"
+)
+
+// Prefix namespaces the buckets and windows of NewTokenBucket and NewSlidingWindow in redis.
+var Prefix = "anansi_ratelimit:"
+
+// ErrRateLimited is the error clients get when they exceed their limit.
+var ErrRateLimited = api.Define(
+	"rate_limited",
+	http.StatusTooManyRequests,
+	"You have made too many requests. Please try again later.",
+)
+
+// Rate is the number of requests allowed within a period.
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+// PerSecond allows n requests every second
+func PerSecond(n int) Rate {
+	return Rate{Limit: n, Period: time.Second}
+}
+
+// PerMinute allows n requests every minute
+func PerMinute(n int) Rate {
+	return Rate{Limit: n, Period: time.Minute}
+}
+
+// PerHour allows n requests every hour
+func PerHour(n int) Rate {
+	return Rate{Limit: n, Period: time.Hour}
+}
+
+// Result describes the state of a key after a request has been counted.
+type Result struct {
+	Allowed    bool
+	Limit      int
+	Remaining  int
+	Reset      time.Duration // time until the limit is fully restored
+	RetryAfter time.Duration // time until the next request is allowed, zero if this one was
+}
+
+// Limiter counts requests by their keys.
+type Limiter interface {
+	// Allow counts a request for the key and reports if it's within the limit.
+	Allow(ctx context.Context, key string) (Result, error)
+}
+
+// KeyFunc identifies the client making a request. Requests that get an empty
+// key are not limited.
+type KeyFunc func(r *http.Request) string
+
+// ByIP identifies clients by their IP address. Use it after middleware.RealIP
+// if the server runs behind a proxy.
+func ByIP(r *http.Request) string {
+	host, _, err := net.SplitHostPort(r.RemoteAddr)
+	if err != nil {
+		return r.RemoteAddr
+	}
+
+	return host
+}
+
+// BySession identifies clients by the ID their sessions(bearer, cookie or headless)
+// hold, falling back to their IP address for requests without a session. Sessions are
+// only peeked at, so counting requests doesn't keep them alive.
+func BySession[T any](m *sessions.Manager, id func(T) string) KeyFunc {
+	return func(r *http.Request) string {
+		var session T
+		if err := m.Peek(r, &session); err != nil {
+			return ByIP(r)
+		}
+
+		return "session:" + id(session)
+	}
+}
+
+// Limit creates a middleware that limits requests using the limiter, responding with
+// ErrRateLimited once the client exceeds its limit. The RateLimit-Limit, RateLimit-Remaining
+// and RateLimit-Reset headers are set on every response, and Retry-After on rejected ones.
+//
+// Requests are allowed if the limiter fails, so make sure it's used after api.Recoverer.
+func Limit(l Limiter, key KeyFunc) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			k := key(r)
+			if k == "" {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			res, err := l.Allow(r.Context(), k)
+			if err != nil {
+				zerolog.Ctx(r.Context()).Err(err).Msg("failed to check rate limit")
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			w.Header().Set("RateLimit-Limit", strconv.Itoa(res.Limit))
+			w.Header().Set("RateLimit-Remaining", strconv.Itoa(res.Remaining))
+			w.Header().Set("RateLimit-Reset", seconds(res.Reset))
+
+			if !res.Allowed {
+				w.Header().Set("Retry-After", seconds(res.RetryAfter))
+				panic(ErrRateLimited)
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// seconds rounds up the duration to whole seconds
+func seconds(d time.Duration) string {
+	return strconv.Itoa(int(math.Ceil(d.Seconds())))
+}
@@ -0,0 +1,164 @@
+package ratelimit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/noxecane/anansi/api"
+	"github.com/noxecane/anansi/json"
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/ksuid"
+)
+
+var client *redis.Client
+var ctx = context.TODO()
+
+func TestMain(m *testing.M) {
+	client = redis.NewClient(&redis.Options{
+		Addr: "localhost:6379",
+		DB:   0,
+	})
+
+	// test the connection
+	if _, err := client.Ping(ctx).Result(); err != nil {
+		panic(err)
+	}
+
+	defer os.Exit(m.Run())
+
+	if err := client.Close(); err != nil {
+		panic(err)
+	}
+}
+
+func TestLimiters(t *testing.T) {
+	rate := Rate{Limit: 3, Period: 500 * time.Millisecond}
+	limiters := map[string]Limiter{
+		"redis token bucket":    NewTokenBucket(client, rate),
+		"memory token bucket":   NewMemoryTokenBucket(rate),
+		"redis sliding window":  NewSlidingWindow(client, rate),
+		"memory sliding window": NewMemorySlidingWindow(rate),
+	}
+
+	for name, l := range limiters {
+		t.Run(name+" allows requests up to the limit", func(t *testing.T) {
+			key := ksuid.New().String()
+
+			for i := 0; i < rate.Limit; i++ {
+				res, err := l.Allow(ctx, key)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if !res.Allowed {
+					t.Fatalf("Expected request %d to be allowed", i+1)
+				}
+
+				if res.Remaining != rate.Limit-i-1 {
+					t.Errorf("Expected %d requests to remain, got %d", rate.Limit-i-1, res.Remaining)
+				}
+			}
+
+			res, err := l.Allow(ctx, key)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if res.Allowed {
+				t.Error("Expected requests over the limit to be rejected")
+			}
+
+			if res.RetryAfter <= 0 || res.RetryAfter > rate.Period {
+				t.Errorf("Expected RetryAfter to be within %s, got %s", rate.Period, res.RetryAfter)
+			}
+
+			time.Sleep(rate.Period)
+
+			if res, _ = l.Allow(ctx, key); !res.Allowed {
+				t.Error("Expected requests to be allowed after the period")
+			}
+		})
+
+		t.Run(name+" limits keys separately", func(t *testing.T) {
+			key := ksuid.New().String()
+			for i := 0; i < rate.Limit; i++ {
+				_, _ = l.Allow(ctx, key)
+			}
+
+			res, err := l.Allow(ctx, ksuid.New().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !res.Allowed {
+				t.Error("Expected a different key to be allowed")
+			}
+		})
+	}
+}
+
+func TestLimit(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(api.Recoverer("production"))
+	router.Use(Limit(NewMemorySlidingWindow(PerMinute(2)), ByIP))
+	router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+		api.Success(r, w, nil)
+	})
+
+	send := func(ip string) *httptest.ResponseRecorder {
+		res := httptest.NewRecorder()
+		req := httptest.NewRequest("GET", "/", nil)
+		req.RemoteAddr = ip + ":4000"
+		router.ServeHTTP(res, req)
+
+		return res
+	}
+
+	t.Run("sets the rate limit headers", func(t *testing.T) {
+		res := send("10.0.0.1")
+
+		if res.Code != http.StatusOK {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusOK, res.Code)
+		}
+
+		if res.Header().Get("RateLimit-Limit") != "2" {
+			t.Errorf("Expected RateLimit-Limit to be %s, got %s", "2", res.Header().Get("RateLimit-Limit"))
+		}
+
+		if res.Header().Get("RateLimit-Remaining") != "1" {
+			t.Errorf("Expected RateLimit-Remaining to be %s, got %s", "1", res.Header().Get("RateLimit-Remaining"))
+		}
+
+		if res.Header().Get("RateLimit-Reset") != "60" {
+			t.Errorf("Expected RateLimit-Reset to be %s, got %s", "60", res.Header().Get("RateLimit-Reset"))
+		}
+	})
+
+	t.Run("responds with 429 over the limit", func(t *testing.T) {
+		send("10.0.0.2")
+		send("10.0.0.2")
+		res := send("10.0.0.2")
+
+		if res.Code != http.StatusTooManyRequests {
+			t.Fatalf("Expected the status code to be %d, got %d", http.StatusTooManyRequests, res.Code)
+		}
+
+		if res.Header().Get("Retry-After") == "" {
+			t.Error("Expected the Retry-After header to be set")
+		}
+
+		var e api.Err
+		if err := json.Unmarshal(res.Body.Bytes(), &e); err != nil {
+			t.Fatal(err)
+		}
+
+		if e.Kind != ErrRateLimited.Kind {
+			t.Errorf("Expected the error code to be %s, got %s", ErrRateLimited.Kind, e.Kind)
+		}
+	})
+}
@@ -0,0 +1,118 @@
+package ratelimit
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/noxecane/anansi/internal/expiring"
+	"github.com/redis/go-redis/v9"
+	"github.com/segmentio/ksuid"
+)
+
+// slidingWindowScript drops requests older than the window and logs the current one
+// if the window isn't full.
+var slidingWindowScript = redis.NewScript(`
+local limit = tonumber(ARGV[1])
+local period = tonumber(ARGV[2])
+local now = tonumber(ARGV[3])
+
+redis.call("ZREMRANGEBYSCORE", KEYS[1], "-inf", now - period)
+
+local count = redis.call("ZCARD", KEYS[1])
+local allowed = 0
+if count < limit then
+	redis.call("ZADD", KEYS[1], now, ARGV[4])
+	count = count + 1
+	allowed = 1
+end
+
+redis.call("PEXPIRE", KEYS[1], period)
+
+local oldest = redis.call("ZRANGE", KEYS[1], 0, 0, "WITHSCORES")
+return {allowed, count, tonumber(oldest[2]) or now}
+`)
+
+type redisSlidingWindow struct {
+	redis *redis.Client
+	rate  Rate
+}
+
+// NewSlidingWindow creates a sliding window Limiter that logs request times in a redis
+// sorted set per key. It allows at most rate.Limit requests in any rate.Period, at the
+// cost of storing every request made within the period.
+func NewSlidingWindow(r *redis.Client, rate Rate) Limiter {
+	return &redisSlidingWindow{redis: r, rate: rate}
+}
+
+func (sw *redisSlidingWindow) Allow(ctx context.Context, key string) (Result, error) {
+	now := time.Now()
+
+	res, err := slidingWindowScript.Run(ctx, sw.redis, []string{Prefix + "window:" + key},
+		sw.rate.Limit, sw.rate.Period.Milliseconds(), now.UnixMilli(), ksuid.New().String(),
+	).Int64Slice()
+	if err != nil {
+		return Result{}, err
+	}
+
+	return windowResult(sw.rate, res[0] == 1, int(res[1]), now.Sub(time.UnixMilli(res[2]))), nil
+}
+
+type memorySlidingWindow struct {
+	mu      sync.Mutex
+	rate    Rate
+	windows *expiring.Map[[]time.Time]
+}
+
+// NewMemorySlidingWindow creates a sliding window Limiter that keeps request times in
+// memory. Every instance counts requests separately, so each allows clients the full rate.
+func NewMemorySlidingWindow(rate Rate) Limiter {
+	return &memorySlidingWindow{rate: rate, windows: expiring.New[[]time.Time](rate.Period)}
+}
+
+func (sw *memorySlidingWindow) Allow(_ context.Context, key string) (Result, error) {
+	sw.mu.Lock()
+	defer sw.mu.Unlock()
+
+	now := time.Now()
+	start := now.Add(-sw.rate.Period)
+
+	window, _ := sw.windows.Get(key, now)
+	i := 0
+	for i < len(window) && !window[i].After(start) {
+		i++
+	}
+	window = window[i:]
+
+	allowed := len(window) < sw.rate.Limit
+	if allowed {
+		window = append(window, now)
+	}
+
+	if len(window) == 0 {
+		sw.windows.Delete(key)
+		return windowResult(sw.rate, allowed, 0, 0), nil
+	}
+
+	// every request in the window is out of it a period after the last one
+	sw.windows.Set(key, window, now, sw.rate.Period)
+
+	return windowResult(sw.rate, allowed, len(window), now.Sub(window[0])), nil
+}
+
+// windowResult computes the result from the number of requests in the window and
+// the age of the oldest.
+func windowResult(rate Rate, allowed bool, count int, age time.Duration) Result {
+	res := Result{
+		Allowed:   allowed,
+		Limit:     rate.Limit,
+		Remaining: rate.Limit - count,
+		Reset:     rate.Period - age,
+	}
+
+	if !allowed {
+		res.RetryAfter = res.Reset
+	}
+
+	return res
+}
@@ -0,0 +1,235 @@
+package anansi
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Redacted replaces the values of sensitive headers and fields in logs
+const Redacted = "[REDACTED]"
+
+var (
+	// SensitiveHeaders are headers whose values are never logged
+	SensitiveHeaders = []string{
+		"Authorization", "Proxy-Authorization", "Cookie", "Set-Cookie", "X-Api-Key", "X-Auth-Token", "X-Csrf-Token",
+	}
+	// SensitiveKeys are JSON keys whose values are never logged, no matter how deeply
+	// they are nested. They are matched regardless of case.
+	SensitiveKeys = []string{
+		"password", "password_confirmation", "current_password", "new_password",
+		"token", "access_token", "refresh_token", "id_token",
+		"secret", "client_secret", "api_key", "otp",
+	}
+	// SensitivePaths are dot separated JSON paths whose values are never logged, where *
+	// matches any key or array index, e.g. "user.ssn" or "cards.*.number"
+	SensitivePaths []string
+
+	redactedTypes sync.Map // reflect.Type -> map[string]jsonField
+)
+
+// RedactHeaders flattens the headers for logging like SimpleMap, with the values of
+// SensitiveHeaders replaced.
+func RedactHeaders(h http.Header) map[string]interface{} {
+	result := SimpleMap(h)
+
+	for _, name := range SensitiveHeaders {
+		key := strings.ToLower(name)
+		if _, ok := result[key]; ok {
+			result[key] = Redacted
+		}
+	}
+
+	return result
+}
+
+// RedactJSON compacts the JSON for logging, replacing values of SensitiveKeys, SensitivePaths
+// and the fields of v tagged with `log:"redact"`. v is the value raw was marshalled from,
+// and can be nil if there's none.
+func RedactJSON(raw []byte, v interface{}) ([]byte, error) {
+	dec := json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+
+	var tree interface{}
+	if err := dec.Decode(&tree); err != nil {
+		return nil, err
+	}
+
+	var t reflect.Type
+	if v != nil {
+		t = reflect.TypeOf(v)
+	}
+
+	tree = redactTree(tree, t, nil, splitPaths(SensitivePaths))
+
+	buffer := new(bytes.Buffer)
+	enc := json.NewEncoder(buffer)
+	enc.SetEscapeHTML(false)
+
+	if err := enc.Encode(tree); err != nil {
+		return nil, err
+	}
+
+	return bytes.TrimSuffix(buffer.Bytes(), []byte("\n")), nil
+}
+
+// redactTree replaces sensitive values in the decoded JSON. t is the type the JSON
+// was marshalled from, used to find fields tagged with `log:"redact"`. It's nil
+// when unknown.
+func redactTree(node interface{}, t reflect.Type, path []string, paths [][]string) interface{} {
+	t = elemType(t)
+
+	switch n := node.(type) {
+	case map[string]interface{}:
+		var fields map[string]jsonField
+		if t != nil && t.Kind() == reflect.Struct {
+			fields = jsonFields(t)
+		}
+
+		for k, child := range n {
+			childPath := appendPath(path, k)
+			childType := childType(t, fields, k)
+
+			if isSensitiveKey(k) || fields[k].redact || matchesAny(childPath, paths) {
+				n[k] = Redacted
+			} else {
+				n[k] = redactTree(child, childType, childPath, paths)
+			}
+		}
+	case []interface{}:
+		var elem reflect.Type
+		if t != nil && (t.Kind() == reflect.Slice || t.Kind() == reflect.Array) {
+			elem = t.Elem()
+		}
+
+		for i, child := range n {
+			childPath := appendPath(path, strconv.Itoa(i))
+			if matchesAny(childPath, paths) {
+				n[i] = Redacted
+			} else {
+				n[i] = redactTree(child, elem, childPath, paths)
+			}
+		}
+	}
+
+	return node
+}
+
+func childType(t reflect.Type, fields map[string]jsonField, key string) reflect.Type {
+	switch {
+	case t == nil:
+		return nil
+	case fields != nil:
+		return fields[key].typ
+	case t.Kind() == reflect.Map:
+		return t.Elem()
+	default:
+		return nil
+	}
+}
+
+func isSensitiveKey(key string) bool {
+	for _, k := range SensitiveKeys {
+		if strings.EqualFold(k, key) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func matchesAny(path []string, paths [][]string) bool {
+	for _, p := range paths {
+		if len(p) != len(path) {
+			continue
+		}
+
+		matched := true
+		for i := range p {
+			if p[i] != "*" && p[i] != path[i] {
+				matched = false
+				break
+			}
+		}
+
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+func splitPaths(paths []string) [][]string {
+	split := make([][]string, len(paths))
+	for i, p := range paths {
+		split[i] = strings.Split(p, ".")
+	}
+
+	return split
+}
+
+// appendPath copies the path so siblings don't share the backing array
+func appendPath(path []string, key string) []string {
+	p := make([]string, len(path)+1)
+	copy(p, path)
+	p[len(path)] = key
+
+	return p
+}
+
+type jsonField struct {
+	typ    reflect.Type
+	redact bool
+}
+
+// jsonFields maps the JSON names of the fields of a struct to their types, and
+// whether they are tagged with `log:"redact"`
+func jsonFields(t reflect.Type) map[string]jsonField {
+	if fields, ok := redactedTypes.Load(t); ok {
+		return fields.(map[string]jsonField)
+	}
+
+	fields := make(map[string]jsonField)
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		// fields of embedded structs are promoted
+		if f.Anonymous && name == "" && elemType(f.Type).Kind() == reflect.Struct {
+			for k, v := range jsonFields(elemType(f.Type)) {
+				if _, ok := fields[k]; !ok {
+					fields[k] = v
+				}
+			}
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+		fields[name] = jsonField{typ: f.Type, redact: f.Tag.Get("log") == "redact"}
+	}
+
+	redactedTypes.Store(t, fields)
+	return fields
+}
+
+func elemType(t reflect.Type) reflect.Type {
+	for t != nil && t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	return t
+}
@@ -0,0 +1,118 @@
+package anansi
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+)
+
+type redactCard struct {
+	Number string `json:"number" log:"redact"`
+	Brand  string `json:"brand"`
+}
+
+type redactUser struct {
+	Name     string       `json:"name"`
+	Password string       `json:"password"`
+	Cards    []redactCard `json:"cards"`
+	Manager  *redactUser  `json:"manager"`
+}
+
+func TestRedactHeaders(t *testing.T) {
+	h := http.Header{}
+	h.Set("Authorization", "Bearer secret")
+	h.Set("Cookie", "session=secret")
+	h.Set("Content-Type", "application/json")
+
+	redacted := RedactHeaders(h)
+
+	if redacted["authorization"] != Redacted || redacted["cookie"] != Redacted {
+		t.Errorf("Expected auth headers to be redacted, got %v", redacted)
+	}
+
+	if redacted["content-type"] != "application/json" {
+		t.Errorf("Expected content-type to be %s, got %v", "application/json", redacted["content-type"])
+	}
+}
+
+func TestRedactJSON(t *testing.T) {
+	redact := func(raw []byte, v interface{}) map[string]interface{} {
+		redacted, err := RedactJSON(raw, v)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var m map[string]interface{}
+		if err := json.Unmarshal(redacted, &m); err != nil {
+			t.Fatal(err)
+		}
+
+		return m
+	}
+
+	t.Run("redacts sensitive keys at any depth", func(t *testing.T) {
+		m := redact([]byte(`{"user": {"Password": "secret", "name": "ada"}, "access_token": "secret"}`), nil)
+
+		user := m["user"].(map[string]interface{})
+		if user["Password"] != Redacted || m["access_token"] != Redacted {
+			t.Errorf("Expected the password and token to be redacted, got %v", m)
+		}
+
+		if user["name"] != "ada" {
+			t.Errorf("Expected the name to be %s, got %v", "ada", user["name"])
+		}
+	})
+
+	t.Run("redacts sensitive paths", func(t *testing.T) {
+		SensitivePaths = []string{"user.ssn", "accounts.*.iban"}
+		defer func() { SensitivePaths = nil }()
+
+		m := redact([]byte(`{"user": {"ssn": "123"}, "ssn": "456", "accounts": [{"iban": "NG01"}]}`), nil)
+
+		if m["user"].(map[string]interface{})["ssn"] != Redacted {
+			t.Error("Expected user.ssn to be redacted")
+		}
+
+		if m["ssn"] != "456" {
+			t.Errorf("Expected ssn outside the path to be %s, got %v", "456", m["ssn"])
+		}
+
+		account := m["accounts"].([]interface{})[0].(map[string]interface{})
+		if account["iban"] != Redacted {
+			t.Error("Expected accounts.*.iban to be redacted")
+		}
+	})
+
+	t.Run("redacts fields tagged with log:redact", func(t *testing.T) {
+		u := redactUser{
+			Name:    "ada",
+			Cards:   []redactCard{{Number: "4111", Brand: "visa"}},
+			Manager: &redactUser{Cards: []redactCard{{Number: "5500"}}},
+		}
+		raw, _ := json.Marshal(u)
+
+		m := redact(raw, u)
+
+		card := m["cards"].([]interface{})[0].(map[string]interface{})
+		if card["number"] != Redacted || card["brand"] != "visa" {
+			t.Errorf("Expected only the card number to be redacted, got %v", card)
+		}
+
+		manager := m["manager"].(map[string]interface{})
+		managerCard := manager["cards"].([]interface{})[0].(map[string]interface{})
+		if managerCard["number"] != Redacted {
+			t.Errorf("Expected nested card numbers to be redacted, got %v", managerCard)
+		}
+	})
+
+	t.Run("keeps large numbers intact", func(t *testing.T) {
+		redacted, err := RedactJSON([]byte(`{"id": 9007199254740993}`), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(redacted) != `{"id":9007199254740993}` {
+			t.Errorf("Expected the ID to be unchanged, got %s", redacted)
+		}
+	})
+}
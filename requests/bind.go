@@ -0,0 +1,212 @@
+package requests
+
+import (
+	"encoding"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	ozzo "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// BindError is returned when a value from the request can't be converted to the
+// type of the field it's bound to.
+type BindError struct {
+	Source string // one of path, header or query
+	Name   string // name of the param, header or query key
+	Value  string
+	Type   reflect.Type
+	Err    error
+}
+
+func (e *BindError) Error() string {
+	return fmt.Sprintf("%s %s: cannot convert %q to %s", e.Source, e.Name, e.Value, e.Type)
+}
+
+func (e *BindError) Unwrap() error { return e.Err }
+
+var textUnmarshaler = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+
+// Bind fills the struct v from every part of the request in one go. The JSON body is
+// decoded first(only if the request has one), then fields are set from chi URL params,
+// headers and query values using the "path", "header" and "query" tags, e.g.
+//
+//	type bookUpdate struct {
+//		ID     uint64 `path:"id" json:"-"`
+//		Tenant string `header:"X-Tenant" json:"-"`
+//		Notify bool   `query:"notify" json:"-"`
+//		Title  string `json:"title" mod:"trim"`
+//	}
+//
+// It then applies mold transformations and ozzo validation like ReadJSON. Values that
+// can't be converted to the type of their field fail with a *BindError.
+func Bind(r *http.Request, v interface{}) error {
+	if strings.Contains(r.Header.Get("Content-Type"), "application/json") && r.ContentLength != 0 {
+		err := decodeJSON(r, v, decodeOptions(r))
+		if err != nil && err != io.EOF {
+			return err
+		}
+
+		if err == nil {
+			logDecoded(r, v)
+		}
+	}
+
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Pointer || val.Elem().Kind() != reflect.Struct {
+		panic(fmt.Errorf("requests.Bind expects a pointer to a struct, got %T", v))
+	}
+
+	if err := bindStruct(r, val.Elem()); err != nil {
+		return err
+	}
+
+	if err := generalMold.Struct(r.Context(), v); err != nil {
+		return err
+	}
+
+	return ozzo.Validate(v)
+}
+
+func bindStruct(r *http.Request, val reflect.Value) error {
+	var query map[string][]string
+
+	t := val.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		field := val.Field(i)
+
+		// exported fields of embedded structs are promoted, like with encoding/json
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if err := bindStruct(r, field); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		var source, name string
+		var values []string
+
+		switch {
+		case f.Tag.Get("path") != "":
+			source, name = "path", f.Tag.Get("path")
+			if param := chi.URLParam(r, name); param != "" {
+				values = []string{param}
+			}
+		case f.Tag.Get("header") != "":
+			source, name = "header", f.Tag.Get("header")
+			values = r.Header.Values(name)
+		case f.Tag.Get("query") != "":
+			if query == nil {
+				query = r.URL.Query()
+			}
+			source, name = "query", f.Tag.Get("query")
+			values = query[name]
+		default:
+			continue
+		}
+
+		if len(values) == 0 {
+			continue
+		}
+
+		if err := setField(field, values); err != nil {
+			return &BindError{
+				Source: source,
+				Name:   name,
+				Value:  strings.Join(values, ","),
+				Type:   f.Type,
+				Err:    err,
+			}
+		}
+	}
+
+	return nil
+}
+
+// setField converts the values to the type of the field. Slices get every value,
+// other types get the first.
+func setField(field reflect.Value, values []string) error {
+	if field.Kind() == reflect.Slice && field.Type().Elem().Kind() != reflect.Uint8 && !field.Addr().Type().Implements(textUnmarshaler) {
+		slice := reflect.MakeSlice(field.Type(), len(values), len(values))
+		for i, raw := range values {
+			if err := setValue(slice.Index(i), raw); err != nil {
+				return err
+			}
+		}
+		field.Set(slice)
+
+		return nil
+	}
+
+	return setValue(field, values[0])
+}
+
+// setValue parses a string into the value based on its type
+func setValue(v reflect.Value, raw string) error {
+	if v.Kind() == reflect.Pointer {
+		ptr := reflect.New(v.Type().Elem())
+		if err := setValue(ptr.Elem(), raw); err != nil {
+			return err
+		}
+		v.Set(ptr)
+
+		return nil
+	}
+
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshaler) {
+		return v.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(raw))
+	}
+
+	if v.Type() == reflect.TypeOf(time.Duration(0)) {
+		d, err := time.ParseDuration(raw)
+		if err != nil {
+			return err
+		}
+		v.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(n)
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,131 @@
+package requests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	ozzo "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/segmentio/ksuid"
+)
+
+type pagination struct {
+	Limit int `query:"limit"`
+}
+
+type bookUpdate struct {
+	pagination
+	ID        uint64        `path:"id" json:"-"`
+	Tenant    string        `header:"X-Tenant" json:"-" mod:"trim"`
+	Tags      []string      `query:"tag" json:"-"`
+	Notify    *bool         `query:"notify" json:"-"`
+	Timeout   time.Duration `query:"timeout" json:"-"`
+	Published time.Time     `query:"published_at" json:"-"`
+	Author    ksuid.KSUID   `query:"author" json:"-"`
+	Title     string        `json:"title" mod:"trim"`
+}
+
+func (b *bookUpdate) Validate() error {
+	return ozzo.ValidateStruct(b,
+		ozzo.Field(&b.Tenant, ozzo.Required),
+		ozzo.Field(&b.Title, ozzo.Required),
+	)
+}
+
+func TestBind(t *testing.T) {
+	bind := func(target, body string, headers map[string]string) (bookUpdate, error) {
+		var b bookUpdate
+		var err error
+
+		router := chi.NewRouter()
+		router.Put("/books/{id}", func(w http.ResponseWriter, r *http.Request) {
+			err = Bind(r, &b)
+		})
+
+		req := httptest.NewRequest("PUT", target, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range headers {
+			req.Header.Set(k, v)
+		}
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		return b, err
+	}
+
+	t.Run("binds every part of the request", func(t *testing.T) {
+		author := ksuid.New()
+		target := "/books/42?tag=fiction&tag=classic&notify=true&timeout=5s&limit=10" +
+			"&published_at=1958-06-17T00:00:00Z&author=" + author.String()
+
+		b, err := bind(target, `{"title": " Things fall apart "}`, map[string]string{"X-Tenant": " achebe "})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if b.ID != 42 {
+			t.Errorf("Expected the ID to be %d, got %d", 42, b.ID)
+		}
+
+		if b.Tenant != "achebe" {
+			t.Errorf("Expected the tenant to be %s, got %s", "achebe", b.Tenant)
+		}
+
+		if strings.Join(b.Tags, ",") != "fiction,classic" {
+			t.Errorf("Expected the tags to be %s, got %v", "fiction,classic", b.Tags)
+		}
+
+		if b.Notify == nil || !*b.Notify {
+			t.Error("Expected notify to be true")
+		}
+
+		if b.Timeout != 5*time.Second {
+			t.Errorf("Expected the timeout to be %s, got %s", 5*time.Second, b.Timeout)
+		}
+
+		if b.Limit != 10 {
+			t.Errorf("Expected the embedded limit to be %d, got %d", 10, b.Limit)
+		}
+
+		if b.Published.Year() != 1958 {
+			t.Errorf("Expected the year published to be %d, got %d", 1958, b.Published.Year())
+		}
+
+		if b.Author != author {
+			t.Errorf("Expected the author to be %s, got %s", author, b.Author)
+		}
+
+		if b.Title != "Things fall apart" {
+			t.Errorf("Expected the title to be %s, got %s", "Things fall apart", b.Title)
+		}
+	})
+
+	t.Run("fails with typed conversion errors", func(t *testing.T) {
+		_, err := bind("/books/forty-two", `{"title": "Arrow of God"}`, map[string]string{"X-Tenant": "achebe"})
+
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) {
+			t.Fatalf("Expected a *BindError, got %v", err)
+		}
+
+		if bindErr.Source != "path" || bindErr.Name != "id" || bindErr.Type.String() != "uint64" {
+			t.Errorf("Expected the error to be for path id of type uint64, got %s", bindErr)
+		}
+	})
+
+	t.Run("validates the struct", func(t *testing.T) {
+		_, err := bind("/books/42", `{"title": "Arrow of God"}`, nil)
+
+		var errs ozzo.Errors
+		if !errors.As(err, &errs) {
+			t.Fatalf("Expected validation errors, got %v", err)
+		}
+
+		if _, ok := errs["Tenant"]; !ok {
+			t.Errorf("Expected the tenant to fail validation, got %v", errs)
+		}
+	})
+}
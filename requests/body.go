@@ -13,7 +13,6 @@ import (
 	ozzo "github.com/go-ozzo/ozzo-validation/v4"
 	"github.com/go-playground/mold/v4"
 	"github.com/go-playground/mold/v4/modifiers"
-	"github.com/noxecane/anansi/json"
 )
 
 var (
@@ -53,7 +52,8 @@ func ReadBody(r *http.Request) ([]byte, error) {
 // ReadJSON decodes the JSON body of the request and destroys it to prevent possible issues with
 // writing a response. Returns ErrNotJSON if the content-type of the request is not JSON, else
 // it returns validation.Errors if the resultant value fails validation defined using ozzo.
-// Otherwise the it returns an error when json decoding fails
+// Otherwise the it returns an error when json decoding fails, or the body breaks the
+// DecodeOptions of the request(see Decoding).
 func ReadJSON(r *http.Request, v interface{}) error {
 	// make sure we are reading a JSON type
 	contentType := r.Header.Get("Content-Type")
@@ -61,7 +61,7 @@ func ReadJSON(r *http.Request, v interface{}) error {
 		return ErrNotJSON
 	}
 
-	err := json.NewDecoder(r.Body).Decode(v)
+	err := decodeJSON(r, v, decodeOptions(r))
 
 	switch {
 	case err == io.EOF:
@@ -70,6 +70,8 @@ func ReadJSON(r *http.Request, v interface{}) error {
 	case err != nil:
 		return err
 	default:
+		logDecoded(r, v)
+
 		// validate parsed JSON data
 		if err := generalMold.Struct(r.Context(), v); err != nil {
 			return err
@@ -92,7 +94,7 @@ func IDParam(r *http.Request, name string) (uint, error) {
 		panic(errors.New(err))
 	}
 
-	raw, err := strconv.ParseUint(param, 10, 32)
+	raw, err := strconv.ParseUint(param, 10, strconv.IntSize)
 	if err != nil {
 		err := fmt.Sprintf("%s must be an ID", name)
 		return 0, errors.New(err)
@@ -2,50 +2,93 @@ package requests
 
 import (
 	"net/http"
+	"strings"
+	"time"
 
 	"github.com/rs/cors"
 )
 
+// CORSOptions configure how cross-origin requests are handled. They can be loaded with
+// anansi.LoadEnv, so a field named CORS reads CORS_ALLOWED_ORIGINS, CORS_MAX_AGE e.t.c.
+type CORSOptions struct {
+	AllowedOrigins   []string                                  `envconfig:"ALLOWED_ORIGINS"`   // allowed origins, each with at most one * e.g. https://*.example.com
+	AllowOrigin      func(r *http.Request, origin string) bool `ignored:"true"`                // decides on origins AllowedOrigins doesn't match
+	AllowedMethods   []string                                  `envconfig:"ALLOWED_METHODS"`   // allowed methods. Defaults to HEAD, GET, POST, PUT, PATCH and DELETE
+	AllowedHeaders   []string                                  `envconfig:"ALLOWED_HEADERS"`   // request headers clients can send, "*" allows all of them
+	ExposedHeaders   []string                                  `envconfig:"EXPOSED_HEADERS"`   // response headers clients can read
+	AllowCredentials bool                                      `envconfig:"ALLOW_CREDENTIALS"` // allow cookies and authorization headers
+	MaxAge           time.Duration                             `envconfig:"MAX_AGE"`           // how long clients can cache preflight responses
+}
+
+var defaultCORSMethods = []string{
+	http.MethodHead,
+	http.MethodGet,
+	http.MethodPost,
+	http.MethodPut,
+	http.MethodPatch,
+	http.MethodDelete,
+}
+
 // CORS sets CORS for the handler based on the app environment, making the
 // rules lax in dev environment.
 func CORS(appEnv string, origins ...string) func(http.Handler) http.Handler {
 	if appEnv == "dev" {
-		return devCORS().Handler
-	} else {
-		return secureCORS(origins).Handler
+		origins = []string{"*"}
 	}
-}
 
-// devCORS creates a very permissive CORS instance
-func devCORS() *cors.Cors {
-	return cors.New(cors.Options{
-		AllowedOrigins: []string{"*"},
-		AllowedMethods: []string{
-			http.MethodHead,
-			http.MethodGet,
-			http.MethodPost,
-			http.MethodPut,
-			http.MethodPatch,
-			http.MethodDelete,
-		},
+	return CORSWith(CORSOptions{
+		AllowedOrigins:   origins,
 		AllowedHeaders:   []string{"*"},
 		AllowCredentials: true,
 	})
 }
 
-// secureCORS is a lot like DevCORS except with a limited set of origins
-func secureCORS(origins []string) *cors.Cors {
-	return cors.New(cors.Options{
-		AllowedOrigins: origins,
-		AllowedMethods: []string{
-			http.MethodHead,
-			http.MethodGet,
-			http.MethodPost,
-			http.MethodPut,
-			http.MethodPatch,
-			http.MethodDelete,
-		},
-		AllowedHeaders:   []string{"*"},
-		AllowCredentials: true,
-	})
+// CORSWith creates a middleware that handles CORS(including preflight requests) using
+// opts. To use different options for a group of routes, use it on a router created with
+// chi's Route or Mount, as preflight requests never reach middleware added With. Note
+// that CORS middleware further up the stack answers preflight requests first.
+func CORSWith(opts CORSOptions) func(http.Handler) http.Handler {
+	methods := opts.AllowedMethods
+	if len(methods) == 0 {
+		methods = defaultCORSMethods
+	}
+
+	copts := cors.Options{
+		AllowedOrigins:   opts.AllowedOrigins,
+		AllowedMethods:   methods,
+		AllowedHeaders:   opts.AllowedHeaders,
+		ExposedHeaders:   opts.ExposedHeaders,
+		AllowCredentials: opts.AllowCredentials,
+		MaxAge:           int(opts.MaxAge.Seconds()),
+	}
+
+	// cors ignores AllowedOrigins once there's a function
+	if opts.AllowOrigin != nil {
+		patterns := opts.AllowedOrigins
+		copts.AllowOriginRequestFunc = func(r *http.Request, origin string) bool {
+			return matchOrigin(patterns, origin) || opts.AllowOrigin(r, origin)
+		}
+	}
+
+	return cors.New(copts).Handler
+}
+
+// matchOrigin checks origin against patterns the same way cors does for AllowedOrigins
+func matchOrigin(patterns []string, origin string) bool {
+	origin = strings.ToLower(origin)
+
+	for _, p := range patterns {
+		p = strings.ToLower(p)
+		if p == "*" || p == origin {
+			return true
+		}
+
+		prefix, suffix, ok := strings.Cut(p, "*")
+		if ok && len(origin) >= len(prefix)+len(suffix) &&
+			strings.HasPrefix(origin, prefix) && strings.HasSuffix(origin, suffix) {
+			return true
+		}
+	}
+
+	return false
 }
@@ -0,0 +1,127 @@
+package requests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/noxecane/anansi"
+)
+
+func TestCORSWith(t *testing.T) {
+	preflight := func(h http.Handler, path, origin string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("OPTIONS", path, nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "Content-Type")
+
+		res := httptest.NewRecorder()
+		h.ServeHTTP(res, req)
+
+		return res
+	}
+
+	router := chi.NewRouter()
+	api := chi.NewRouter()
+	api.Use(CORSWith(CORSOptions{
+		AllowedOrigins: []string{"https://*.example.com"},
+		AllowOrigin: func(r *http.Request, origin string) bool {
+			return origin == "https://partner.org"
+		},
+		AllowedHeaders: []string{"Content-Type"},
+		ExposedHeaders: []string{"X-Request-Id"},
+		MaxAge:         10 * time.Minute,
+	}))
+	api.Post("/books", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("created"))
+	})
+	router.Mount("/api", api)
+	router.Route("/admin", func(r chi.Router) {
+		r.Use(CORSWith(CORSOptions{AllowedOrigins: []string{"https://admin.example.com"}, AllowCredentials: true}))
+		r.Post("/users", func(w http.ResponseWriter, r *http.Request) {})
+	})
+
+	t.Run("answers preflight requests from wildcard origins", func(t *testing.T) {
+		res := preflight(router, "/api/books", "https://app.example.com")
+
+		if res.Code != http.StatusOK && res.Code != http.StatusNoContent {
+			t.Errorf("Expected a successful preflight, got %d", res.Code)
+		}
+
+		if origin := res.Header().Get("Access-Control-Allow-Origin"); origin != "https://app.example.com" {
+			t.Errorf("Expected Access-Control-Allow-Origin to be https://app.example.com, got %s", origin)
+		}
+
+		if methods := res.Header().Get("Access-Control-Allow-Methods"); methods != "POST" {
+			t.Errorf("Expected Access-Control-Allow-Methods to be POST, got %s", methods)
+		}
+
+		if age := res.Header().Get("Access-Control-Max-Age"); age != "600" {
+			t.Errorf("Expected Access-Control-Max-Age to be 600, got %s", age)
+		}
+	})
+
+	t.Run("uses the origin function", func(t *testing.T) {
+		res := preflight(router, "/api/books", "https://partner.org")
+		if origin := res.Header().Get("Access-Control-Allow-Origin"); origin != "https://partner.org" {
+			t.Errorf("Expected Access-Control-Allow-Origin to be https://partner.org, got %s", origin)
+		}
+
+		res = preflight(router, "/api/books", "https://example.net")
+		if origin := res.Header().Get("Access-Control-Allow-Origin"); origin != "" {
+			t.Errorf("Expected no Access-Control-Allow-Origin, got %s", origin)
+		}
+	})
+
+	t.Run("exposes headers on actual requests", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/api/books", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		if res.Code != http.StatusOK || res.Body.String() != "created" {
+			t.Errorf("Expected the handler to respond with %d, got %d %s", http.StatusOK, res.Code, res.Body.String())
+		}
+
+		if exposed := res.Header().Get("Access-Control-Expose-Headers"); exposed != "X-Request-Id" {
+			t.Errorf("Expected Access-Control-Expose-Headers to be X-Request-Id, got %s", exposed)
+		}
+	})
+
+	t.Run("uses different options per route group", func(t *testing.T) {
+		res := preflight(router, "/admin/users", "https://admin.example.com")
+
+		if creds := res.Header().Get("Access-Control-Allow-Credentials"); creds != "true" {
+			t.Errorf("Expected Access-Control-Allow-Credentials to be true, got %s", creds)
+		}
+	})
+}
+
+func TestCORSOptionsFromEnv(t *testing.T) {
+	t.Setenv("CORS_ALLOWED_ORIGINS", "https://*.example.com,https://example.org")
+	t.Setenv("CORS_ALLOW_CREDENTIALS", "true")
+	t.Setenv("CORS_MAX_AGE", "1h")
+
+	var env struct {
+		CORS CORSOptions
+	}
+
+	if err := anansi.LoadEnv(&env); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(env.CORS.AllowedOrigins, " ") != "https://*.example.com https://example.org" {
+		t.Errorf("Expected the allowed origins to be loaded, got %v", env.CORS.AllowedOrigins)
+	}
+
+	if !env.CORS.AllowCredentials {
+		t.Error("Expected credentials to be allowed")
+	}
+
+	if env.CORS.MaxAge != time.Hour {
+		t.Errorf("Expected max age to be %s, got %s", time.Hour, env.CORS.MaxAge)
+	}
+}
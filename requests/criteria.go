@@ -0,0 +1,242 @@
+package requests
+
+import (
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	ozzo "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+// Op is a comparison applied by a filter
+type Op string
+
+const (
+	Eq       Op = "eq"
+	Ne       Op = "ne"
+	Gt       Op = "gt"
+	Gte      Op = "gte"
+	Lt       Op = "lt"
+	Lte      Op = "lte"
+	In       Op = "in"
+	Contains Op = "contains"
+	// IsNull checks for missing values when its value is true, and present values otherwise
+	IsNull Op = "null"
+)
+
+// FieldType is the type filter values are converted to
+type FieldType int
+
+const (
+	String FieldType = iota
+	Int
+	Float
+	Bool
+	Time
+)
+
+var (
+	ErrFilterField = ozzo.NewError("validation_filter_field_invalid", "cannot be used to filter")
+	ErrFilterOp    = ozzo.NewError("validation_filter_op_invalid", "does not support {{.op}}")
+	ErrFilterValue = ozzo.NewError("validation_filter_value_invalid", "must be a valid {{.type}}")
+	ErrSortField   = ozzo.NewError("validation_sort_field_invalid", "cannot sort by {{.field}}")
+)
+
+var (
+	filterParam    = regexp.MustCompile(`^filter\[([^\]]+)\](?:\[([^\]]+)\])?$`)
+	fieldTypeNames = map[FieldType]string{String: "string", Int: "integer", Float: "number", Bool: "boolean", Time: "time"}
+	defaultOps     = map[FieldType][]Op{
+		String: {Eq, Ne, In, Contains, IsNull},
+		Int:    {Eq, Ne, Gt, Gte, Lt, Lte, In, IsNull},
+		Float:  {Eq, Ne, Gt, Gte, Lt, Lte, IsNull},
+		Bool:   {Eq, Ne, IsNull},
+		Time:   {Eq, Ne, Gt, Gte, Lt, Lte, IsNull},
+	}
+)
+
+// Field declares a field clients can filter or sort an endpoint's results by.
+type Field struct {
+	Type FieldType
+	// Column is the name of the field in the database. Defaults to the name of
+	// the field.
+	Column string
+	// Ops are the filters allowed on the field. Defaults to every op that makes
+	// sense for the type of the field.
+	Ops []Op
+	// Sortable allows sorting by the field
+	Sortable bool
+}
+
+// Fields is the allowlist of fields for an endpoint, keyed by the name used in
+// the query.
+type Fields map[string]Field
+
+// Condition is a single filter on a column. Value has the go type of the field
+// (e.g. int64 for Int), a slice of them for In and a bool for IsNull.
+type Condition struct {
+	Column string
+	Op     Op
+	Value  any
+}
+
+// Order sorts by a column
+type Order struct {
+	Column string
+	Desc   bool
+}
+
+// Criteria is the parsed form of the filter and sort query params. Conditions
+// are meant to be combined with AND.
+type Criteria struct {
+	Where   []Condition
+	OrderBy []Order
+}
+
+// ReadCriteria parses filters in the form filter[field][op]=value(op defaults to eq)
+// and sorting in the form sort=-created_at,name from the request's query. Only the
+// declared fields can be used. It returns validation.Errors keyed by the query param
+// for any violation.
+func ReadCriteria(r *http.Request, fields Fields) (Criteria, error) {
+	var c Criteria
+	query := r.URL.Query()
+	errs := ozzo.Errors{}
+
+	// make the order of conditions predictable
+	keys := make([]string, 0, len(query))
+	for k := range query {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		match := filterParam.FindStringSubmatch(key)
+		if match == nil {
+			continue
+		}
+
+		name, op := match[1], Op(match[2])
+		if op == "" {
+			op = Eq
+		}
+
+		field, ok := fields[name]
+		if !ok {
+			errs[key] = ErrFilterField
+			continue
+		}
+
+		if !field.allows(op) {
+			errs[key] = ErrFilterOp.SetParams(map[string]interface{}{"op": op})
+			continue
+		}
+
+		for _, raw := range query[key] {
+			value, err := field.convert(op, raw)
+			if err != nil {
+				errs[key] = err
+				break
+			}
+
+			c.Where = append(c.Where, Condition{Column: field.column(name), Op: op, Value: value})
+		}
+	}
+
+	for _, name := range strings.Split(query.Get("sort"), ",") {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			continue
+		}
+
+		desc := strings.HasPrefix(name, "-")
+		name = strings.TrimPrefix(name, "-")
+
+		field, ok := fields[name]
+		if !ok || !field.Sortable {
+			errs["sort"] = ErrSortField.SetParams(map[string]interface{}{"field": name})
+			break
+		}
+
+		c.OrderBy = append(c.OrderBy, Order{Column: field.column(name), Desc: desc})
+	}
+
+	if len(errs) > 0 {
+		return Criteria{}, errs
+	}
+
+	return c, nil
+}
+
+func (f Field) column(name string) string {
+	if f.Column == "" {
+		return name
+	}
+
+	return f.Column
+}
+
+func (f Field) allows(op Op) bool {
+	ops := f.Ops
+	if len(ops) == 0 {
+		ops = defaultOps[f.Type]
+	}
+
+	for _, o := range ops {
+		if o == op {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (f Field) convert(op Op, raw string) (any, error) {
+	switch op {
+	case IsNull:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, ErrFilterValue.SetParams(map[string]interface{}{"type": fieldTypeNames[Bool]})
+		}
+		return b, nil
+	case In:
+		var values []any
+		for _, part := range strings.Split(raw, ",") {
+			v, err := f.value(part)
+			if err != nil {
+				return nil, err
+			}
+			values = append(values, v)
+		}
+		return values, nil
+	default:
+		return f.value(raw)
+	}
+}
+
+func (f Field) value(raw string) (any, error) {
+	var v any
+	var err error
+
+	switch f.Type {
+	case Int:
+		v, err = strconv.ParseInt(raw, 10, 64)
+	case Float:
+		v, err = strconv.ParseFloat(raw, 64)
+	case Bool:
+		v, err = strconv.ParseBool(raw)
+	case Time:
+		if v, err = time.Parse(time.RFC3339, raw); err != nil {
+			v, err = time.Parse("2006-01-02", raw)
+		}
+	default:
+		v = raw
+	}
+
+	if err != nil {
+		return nil, ErrFilterValue.SetParams(map[string]interface{}{"type": fieldTypeNames[f.Type]})
+	}
+
+	return v, nil
+}
@@ -0,0 +1,75 @@
+package requests
+
+import (
+	"errors"
+	"net/http/httptest"
+	"testing"
+
+	ozzo "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+var bookFields = Fields{
+	"title":      {Type: String},
+	"pages":      {Type: Int, Sortable: true},
+	"created_at": {Type: Time, Column: "books.created_at", Sortable: true},
+	"genre":      {Type: String, Ops: []Op{Eq}},
+}
+
+func TestReadCriteria(t *testing.T) {
+	t.Run("parses filters and sorting", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/books?filter[pages][gte]=100&filter[title]=Arrow&filter[genre][eq]=fiction&sort=-created_at,pages", nil)
+
+		c, err := ReadCriteria(req, bookFields)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(c.Where) != 3 {
+			t.Fatalf("Expected 3 conditions, got %d", len(c.Where))
+		}
+
+		pages := c.Where[1]
+		if pages.Column != "pages" || pages.Op != Gte || pages.Value != int64(100) {
+			t.Errorf("Expected pages >= 100, got %v", pages)
+		}
+
+		if c.Where[2].Op != Eq {
+			t.Errorf("Expected op to default to eq, got %s", c.Where[2].Op)
+		}
+
+		if len(c.OrderBy) != 2 || c.OrderBy[0].Column != "books.created_at" || !c.OrderBy[0].Desc {
+			t.Errorf("Expected to sort by books.created_at descending first, got %v", c.OrderBy)
+		}
+	})
+
+	t.Run("converts in filters to lists", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/books?filter[pages][in]=1,2,3", nil)
+
+		c, err := ReadCriteria(req, bookFields)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		values, ok := c.Where[0].Value.([]any)
+		if !ok || len(values) != 3 {
+			t.Errorf("Expected 3 values, got %v", c.Where[0].Value)
+		}
+	})
+
+	t.Run("rejects fields and ops not allowed", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/books?filter[author]=x&filter[genre][ne]=y&filter[pages][gt]=many&sort=title", nil)
+
+		_, err := ReadCriteria(req, bookFields)
+
+		var e ozzo.Errors
+		if !errors.As(err, &e) {
+			t.Fatalf("Expected ReadCriteria to fail with validation errors, got %v", err)
+		}
+
+		for _, key := range []string{"filter[author]", "filter[genre][ne]", "filter[pages][gt]", "sort"} {
+			if e[key] == nil {
+				t.Errorf("Expected %s to be invalid", key)
+			}
+		}
+	})
+}
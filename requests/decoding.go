@@ -0,0 +1,146 @@
+package requests
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/noxecane/anansi/json"
+)
+
+var (
+	ErrTrailingData = errors.New("body has data after the JSON value")
+	ErrTooDeep      = errors.New("body is nested too deeply")
+)
+
+// DecodeOptions control how strictly ReadJSON decodes request bodies. The zero
+// value decodes bodies of any size the same way encoding/json does.
+type DecodeOptions struct {
+	MaxBytes              int64 // maximum size of the body. Larger bodies fail with a *http.MaxBytesError
+	DisallowUnknownFields bool  // fail on fields the value doesn't have
+	DisallowTrailingData  bool  // fail on anything but whitespace after the JSON value
+	MaxDepth              int   // maximum nesting of objects and arrays
+}
+
+// DecodeDefaults are the DecodeOptions used for requests without the Decoding middleware
+var DecodeDefaults DecodeOptions
+
+type decodeKey struct{}
+
+// Decoding is a middleware that sets the DecodeOptions for the routes it's used on,
+// replacing DecodeDefaults or the options of an earlier Decoding. The request body is
+// capped to opts.MaxBytes by the readers(ReadJSON, Bind, Upload and anything that uses
+// LimitBody) rather than here, so routes can raise a limit set for the entire router.
+func Decoding(opts DecodeOptions) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), decodeKey{}, opts)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func decodeOptions(r *http.Request) DecodeOptions {
+	if opts, ok := r.Context().Value(decodeKey{}).(DecodeOptions); ok {
+		return opts
+	}
+
+	return DecodeDefaults
+}
+
+// LimitBody caps the body of r to the MaxBytes of its DecodeOptions(see Decoding), if
+// there's a limit. Reading past it fails with a *http.MaxBytesError. Use it before reading
+// the body by other means than ReadJSON, Bind and Upload.
+func LimitBody(r *http.Request) {
+	limitBody(r, decodeOptions(r))
+}
+
+// limitBody caps the body of r to opts.MaxBytes, if there's a limit
+func limitBody(r *http.Request, opts DecodeOptions) {
+	if opts.MaxBytes > 0 {
+		r.Body = http.MaxBytesReader(nil, r.Body, opts.MaxBytes)
+	}
+}
+
+// decodeJSON decodes the body into v according to opts. It returns io.EOF for
+// empty bodies.
+func decodeJSON(r *http.Request, v interface{}, opts DecodeOptions) error {
+	if opts == (DecodeOptions{}) {
+		return json.NewDecoder(r.Body).Decode(v)
+	}
+
+	// don't bother reading bodies we know are too large
+	if opts.MaxBytes > 0 && r.ContentLength > opts.MaxBytes {
+		return &http.MaxBytesError{Limit: opts.MaxBytes}
+	}
+	limitBody(r, opts)
+
+	raw, err := io.ReadAll(r.Body)
+	if err != nil {
+		return err
+	}
+
+	if len(bytes.TrimSpace(raw)) == 0 {
+		return io.EOF
+	}
+
+	if opts.MaxDepth > 0 && tooDeep(raw, opts.MaxDepth) {
+		return ErrTooDeep
+	}
+
+	reader := bytes.NewReader(raw)
+	dec := json.NewDecoder(reader)
+	if opts.DisallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+
+	if opts.DisallowTrailingData {
+		rest, _ := io.ReadAll(io.MultiReader(dec.Buffered(), reader))
+		if len(bytes.TrimSpace(rest)) > 0 {
+			return ErrTrailingData
+		}
+	}
+
+	return nil
+}
+
+// tooDeep checks if objects and arrays in the JSON are nested beyond max
+func tooDeep(raw []byte, max int) bool {
+	depth := 0
+	inString := false
+	escaped := false
+
+	for _, c := range raw {
+		if inString {
+			switch {
+			case escaped:
+				escaped = false
+			case c == '\\':
+				escaped = true
+			case c == '"':
+				inString = false
+			}
+			continue
+		}
+
+		switch c {
+		case '"':
+			inString = true
+		case '{', '[':
+			depth++
+			if depth > max {
+				return true
+			}
+		case '}', ']':
+			depth--
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,134 @@
+package requests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestDecoding(t *testing.T) {
+	type book struct {
+		Name string      `json:"name"`
+		Tags interface{} `json:"tags"`
+	}
+
+	read := func(opts DecodeOptions, body string) error {
+		var err error
+
+		router := chi.NewRouter()
+		router.With(Decoding(opts)).Post("/", func(w http.ResponseWriter, r *http.Request) {
+			var b book
+			err = ReadJSON(r, &b)
+		})
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		return err
+	}
+
+	t.Run("rejects bodies over the limit", func(t *testing.T) {
+		var tooLarge *http.MaxBytesError
+
+		err := read(DecodeOptions{MaxBytes: 16}, `{"name": "Things fall apart"}`)
+		if !errors.As(err, &tooLarge) {
+			t.Errorf("Expected a *http.MaxBytesError, got %v", err)
+		}
+
+		if err := read(DecodeOptions{MaxBytes: 64}, `{"name": "Things fall apart"}`); err != nil {
+			t.Errorf("Expected bodies within the limit to pass, got %v", err)
+		}
+	})
+
+	t.Run("rejects bodies of unknown size over the limit", func(t *testing.T) {
+		var err error
+		var b book
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name": "Things fall apart"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.ContentLength = -1
+
+		router := chi.NewRouter()
+		router.With(Decoding(DecodeOptions{MaxBytes: 16})).Post("/", func(w http.ResponseWriter, r *http.Request) {
+			err = ReadJSON(r, &b)
+		})
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		var tooLarge *http.MaxBytesError
+		if !errors.As(err, &tooLarge) {
+			t.Errorf("Expected a *http.MaxBytesError, got %v", err)
+		}
+	})
+
+	t.Run("lets routes raise the limit of the router", func(t *testing.T) {
+		var err error
+		var b book
+
+		router := chi.NewRouter()
+		router.Use(Decoding(DecodeOptions{MaxBytes: 16}))
+		router.With(Decoding(DecodeOptions{MaxBytes: 1024})).Post("/", func(w http.ResponseWriter, r *http.Request) {
+			err = ReadJSON(r, &b)
+		})
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{"name": "Things fall apart"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.ContentLength = -1
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		if err != nil {
+			t.Errorf("Expected the route's limit to be used, got %v", err)
+		}
+	})
+
+	t.Run("rejects unknown fields", func(t *testing.T) {
+		if err := read(DecodeOptions{DisallowUnknownFields: true}, `{"name": "Arrow of God", "age": 2}`); err == nil {
+			t.Error("Expected unknown fields to be rejected")
+		}
+
+		if err := read(DecodeOptions{}, `{"name": "Arrow of God", "age": 2}`); err != nil {
+			t.Errorf("Expected unknown fields to be ignored by default, got %v", err)
+		}
+	})
+
+	t.Run("rejects trailing data", func(t *testing.T) {
+		opts := DecodeOptions{DisallowTrailingData: true}
+
+		if err := read(opts, `{"name": "Arrow of God"} {"name": "extra"}`); err != ErrTrailingData {
+			t.Errorf("Expected ErrTrailingData, got %v", err)
+		}
+
+		if err := read(opts, "{\"name\": \"Arrow of God\"}\n  "); err != nil {
+			t.Errorf("Expected trailing whitespace to pass, got %v", err)
+		}
+	})
+
+	t.Run("limits nesting depth", func(t *testing.T) {
+		opts := DecodeOptions{MaxDepth: 3}
+
+		if err := read(opts, `{"name": "[[[[", "tags": [["fiction"]]}`); err != nil {
+			t.Errorf("Expected bodies within the depth to pass, got %v", err)
+		}
+
+		if err := read(opts, `{"tags": [[["fiction"]]]}`); err != ErrTooDeep {
+			t.Errorf("Expected ErrTooDeep, got %v", err)
+		}
+	})
+
+	t.Run("uses DecodeDefaults without the middleware", func(t *testing.T) {
+		DecodeDefaults = DecodeOptions{DisallowUnknownFields: true}
+		defer func() { DecodeDefaults = DecodeOptions{} }()
+
+		req := httptest.NewRequest("POST", "/", strings.NewReader(`{"age": 2}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		var b book
+		if err := ReadJSON(req, &b); err == nil {
+			t.Error("Expected unknown fields to be rejected")
+		}
+	})
+}
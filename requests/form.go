@@ -0,0 +1,262 @@
+package requests
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// formNode is a key of the form, with the values and nested keys under it
+type formNode struct {
+	values   []string
+	children map[string]*formNode
+	brackets bool // set for keys like tags[], which are always lists
+}
+
+func (n *formNode) child(key string) *formNode {
+	if n.children == nil {
+		n.children = make(map[string]*formNode)
+	}
+
+	c, ok := n.children[key]
+	if !ok {
+		c = &formNode{}
+		n.children[key] = c
+	}
+
+	return c
+}
+
+// DecodeForm decodes query or form values into v, which must be a pointer. Keys
+// match the "json" tag of struct fields(or their names) exactly, and nested keys use
+// brackets, so the following are all supported:
+//
+//	tag=a&tag=b          -> Tags []string `json:"tag"`
+//	tags[]=a             -> Tags []string `json:"tags"`
+//	user[name]=x         -> User struct{ Name string `json:"name"` } `json:"user"`
+//	meta[color]=red      -> Meta map[string]string `json:"meta"`
+//	items[0][name]=x     -> Items []struct{ Name string `json:"name"` } `json:"items"`
+//
+// Slices get every value of their key, so a single value becomes a one-element slice,
+// while other types get the first value. Values that can't be converted to their field
+// fail with a *BindError.
+func DecodeForm(values map[string][]string, v interface{}) error {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Pointer || val.IsNil() {
+		panic(fmt.Errorf("requests.DecodeForm expects a pointer, got %T", v))
+	}
+
+	root := &formNode{}
+	for key, vals := range values {
+		node := root
+		for _, segment := range splitKey(key) {
+			node = node.child(segment)
+		}
+
+		node.values = append(node.values, vals...)
+		node.brackets = node.brackets || strings.HasSuffix(key, "[]")
+	}
+
+	return decodeNode(root, val.Elem(), "")
+}
+
+// splitKey splits user[address][city] into user, address and city. Keys ending with
+// [] drop the empty segment.
+func splitKey(key string) []string {
+	i := strings.IndexByte(key, '[')
+	if i <= 0 || !strings.HasSuffix(key, "]") {
+		return []string{key}
+	}
+
+	segments := []string{key[:i]}
+	for _, s := range strings.Split(key[i+1:len(key)-1], "][") {
+		if s != "" {
+			segments = append(segments, s)
+		}
+	}
+
+	return segments
+}
+
+func decodeNode(node *formNode, v reflect.Value, path string) error {
+	if v.Kind() == reflect.Pointer {
+		if v.IsNil() {
+			v.Set(reflect.New(v.Type().Elem()))
+		}
+
+		return decodeNode(node, v.Elem(), path)
+	}
+
+	// leave types like time.Time to setValue
+	if v.CanAddr() && v.Addr().Type().Implements(textUnmarshaler) {
+		return decodeValue(node, v, path)
+	}
+
+	switch v.Kind() {
+	case reflect.Struct:
+		return decodeStruct(node, v, path)
+	case reflect.Map:
+		return decodeMap(node, v, path)
+	case reflect.Slice, reflect.Array:
+		if v.Type().Elem().Kind() == reflect.Uint8 {
+			return decodeValue(node, v, path)
+		}
+		return decodeSlice(node, v, path)
+	case reflect.Interface:
+		if v.NumMethod() == 0 {
+			v.Set(reflect.ValueOf(node.raw()))
+		}
+		return nil
+	default:
+		return decodeValue(node, v, path)
+	}
+}
+
+func decodeStruct(node *formNode, v reflect.Value, path string) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		// exported fields of embedded structs are promoted, like with encoding/json
+		if f.Anonymous && f.Type.Kind() == reflect.Struct {
+			if err := decodeStruct(node, v.Field(i), path); err != nil {
+				return err
+			}
+			continue
+		}
+
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+
+		if name == "" {
+			name = f.Name
+		}
+
+		child, ok := node.children[name]
+		if !ok {
+			continue
+		}
+
+		if err := decodeNode(child, v.Field(i), joinKey(path, name)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeMap(node *formNode, v reflect.Value, path string) error {
+	t := v.Type()
+	if t.Key().Kind() != reflect.String {
+		return fmt.Errorf("%s: only maps with string keys are supported", path)
+	}
+
+	if v.IsNil() {
+		v.Set(reflect.MakeMap(t))
+	}
+
+	for key, child := range node.children {
+		elem := reflect.New(t.Elem()).Elem()
+		if err := decodeNode(child, elem, joinKey(path, key)); err != nil {
+			return err
+		}
+
+		v.SetMapIndex(reflect.ValueOf(key).Convert(t.Key()), elem)
+	}
+
+	return nil
+}
+
+func decodeSlice(node *formNode, v reflect.Value, path string) error {
+	var items []*formNode
+
+	if len(node.children) > 0 {
+		// indexed keys like items[0][name]
+		indices := make([]int, 0, len(node.children))
+		for key := range node.children {
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 {
+				return &BindError{Source: "form", Name: joinKey(path, key), Value: key, Type: v.Type(), Err: err}
+			}
+			indices = append(indices, i)
+		}
+		sort.Ints(indices)
+
+		for _, i := range indices {
+			items = append(items, node.children[strconv.Itoa(i)])
+		}
+	} else {
+		for _, value := range node.values {
+			items = append(items, &formNode{values: []string{value}})
+		}
+	}
+
+	if v.Kind() == reflect.Array {
+		if len(items) > v.Len() {
+			items = items[:v.Len()]
+		}
+	} else {
+		v.Set(reflect.MakeSlice(v.Type(), len(items), len(items)))
+	}
+
+	for i, item := range items {
+		if err := decodeNode(item, v.Index(i), joinKey(path, strconv.Itoa(i))); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func decodeValue(node *formNode, v reflect.Value, path string) error {
+	if len(node.values) == 0 {
+		return nil
+	}
+
+	raw := node.values[0]
+
+	// empty values leave non-strings as they are
+	if raw == "" && v.Kind() != reflect.String {
+		return nil
+	}
+
+	if err := setValue(v, raw); err != nil {
+		return &BindError{Source: "form", Name: path, Value: raw, Type: v.Type(), Err: err}
+	}
+
+	return nil
+}
+
+// raw converts the node into the value stored in an empty interface. Single values
+// stay strings unless the key used brackets.
+func (n *formNode) raw() interface{} {
+	if len(n.children) > 0 {
+		m := make(map[string]interface{}, len(n.children))
+		for k, c := range n.children {
+			m[k] = c.raw()
+		}
+		return m
+	}
+
+	if len(n.values) == 1 && !n.brackets {
+		return n.values[0]
+	}
+
+	return n.values
+}
+
+func joinKey(path, key string) string {
+	if path == "" {
+		return key
+	}
+
+	return path + "[" + key + "]"
+}
@@ -0,0 +1,152 @@
+package requests
+
+import (
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+)
+
+type formAddress struct {
+	City    string `json:"city"`
+	Country string `json:"country"`
+}
+
+type formItem struct {
+	Name     string `json:"name"`
+	Quantity int    `json:"quantity"`
+}
+
+type formUser struct {
+	Name      string            `json:"name"`
+	Address   formAddress       `json:"address"`
+	Manager   *formAddress      `json:"manager"`
+	Tags      []string          `json:"tag"`
+	Scores    []int             `json:"scores"`
+	Meta      map[string]string `json:"meta"`
+	Items     []formItem        `json:"items"`
+	Since     time.Time         `json:"since"`
+	Extra     interface{}       `json:"extra"`
+	Lists     interface{}       `json:"lists"`
+	Ignored   string            `json:"-"`
+	CamelCase string            `json:"camelCase"`
+}
+
+func decodeQuery(t *testing.T, query string) (formUser, error) {
+	values, err := url.ParseQuery(query)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var u formUser
+	err = DecodeForm(values, &u)
+
+	return u, err
+}
+
+func TestDecodeForm(t *testing.T) {
+	t.Run("decodes one-element and repeated slices the same way", func(t *testing.T) {
+		one, err := decodeQuery(t, "tag=a&scores=1")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		many, err := decodeQuery(t, "tag=a&tag=b&scores[]=1&scores[]=2")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if strings.Join(one.Tags, ",") != "a" || len(one.Scores) != 1 || one.Scores[0] != 1 {
+			t.Errorf("Expected one-element slices, got %v and %v", one.Tags, one.Scores)
+		}
+
+		if strings.Join(many.Tags, ",") != "a,b" || len(many.Scores) != 2 || many.Scores[1] != 2 {
+			t.Errorf("Expected two-element slices, got %v and %v", many.Tags, many.Scores)
+		}
+	})
+
+	t.Run("decodes nested structs and maps", func(t *testing.T) {
+		u, err := decodeQuery(t, "name=ada&address[city]=Lagos&manager[country]=NG&meta[color]=red&meta[size]=xl")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if u.Name != "ada" || u.Address.City != "Lagos" {
+			t.Errorf("Expected the name and city to be set, got %+v", u)
+		}
+
+		if u.Manager == nil || u.Manager.Country != "NG" {
+			t.Errorf("Expected the manager to be set, got %+v", u.Manager)
+		}
+
+		if u.Meta["color"] != "red" || u.Meta["size"] != "xl" {
+			t.Errorf("Expected the meta map to be set, got %v", u.Meta)
+		}
+	})
+
+	t.Run("decodes indexed slices of structs", func(t *testing.T) {
+		u, err := decodeQuery(t, "items[1][name]=pen&items[0][name]=book&items[0][quantity]=2")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if len(u.Items) != 2 || u.Items[0].Name != "book" || u.Items[0].Quantity != 2 || u.Items[1].Name != "pen" {
+			t.Errorf("Expected the items to be in index order, got %+v", u.Items)
+		}
+	})
+
+	t.Run("matches keys case-sensitively", func(t *testing.T) {
+		u, err := decodeQuery(t, "camelCase=yes&NAME=ada&Ignored=no")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if u.CamelCase != "yes" {
+			t.Errorf("Expected camelCase to be %s, got %s", "yes", u.CamelCase)
+		}
+
+		if u.Name != "" || u.Ignored != "" {
+			t.Errorf("Expected NAME and Ignored not to match any field, got %+v", u)
+		}
+	})
+
+	t.Run("keeps single values in interfaces unless brackets are used", func(t *testing.T) {
+		u, err := decodeQuery(t, "extra=a&lists[]=a")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, ok := u.Extra.(string); !ok {
+			t.Errorf("Expected extra to be a string, got %T", u.Extra)
+		}
+
+		if _, ok := u.Lists.([]string); !ok {
+			t.Errorf("Expected lists to be a []string, got %T", u.Lists)
+		}
+	})
+
+	t.Run("fails with typed conversion errors", func(t *testing.T) {
+		_, err := decodeQuery(t, "items[0][quantity]=many")
+
+		var bindErr *BindError
+		if !errors.As(err, &bindErr) {
+			t.Fatalf("Expected a *BindError, got %v", err)
+		}
+
+		if bindErr.Name != "items[0][quantity]" {
+			t.Errorf("Expected the error to be for %s, got %s", "items[0][quantity]", bindErr.Name)
+		}
+	})
+
+	t.Run("parses text types", func(t *testing.T) {
+		u, err := decodeQuery(t, "since=2020-01-02T00:00:00Z")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if u.Since.Year() != 2020 {
+			t.Errorf("Expected the year to be %d, got %d", 2020, u.Since.Year())
+		}
+	})
+}
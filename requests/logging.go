@@ -0,0 +1,86 @@
+package requests
+
+import (
+	"context"
+	"math/rand/v2"
+	"net/http"
+	"path"
+	"regexp"
+	"slices"
+
+	"github.com/noxecane/anansi"
+)
+
+// LogPolicy decides which successful requests the responders log. Failed requests(any
+// status from 400 up) are always logged, whatever the policy says.
+type LogPolicy struct {
+	UserAgents *regexp.Regexp // skip requests from matching user agents
+	Paths      []string       // skip requests whose path matches any of these path.Match patterns
+	Statuses   []int          // skip responses with any of these status codes, error codes are ignored
+	Sample     float64        // fraction of the remaining requests to log. 0 logs all of them
+}
+
+// LogDefaults is the LogPolicy used for requests without the Logging middleware. It
+// skips health checks and metric scrapes(see anansi.DumpLog).
+var LogDefaults = LogPolicy{UserAgents: anansi.DumpLog}
+
+type logPolicyKey struct{}
+
+// Logging is a middleware that sets the LogPolicy for the routes it's used on,
+// replacing LogDefaults.
+func Logging(policy LogPolicy) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := context.WithValue(r.Context(), logPolicyKey{}, policy)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// SampleLogs is a middleware that only logs the given fraction of requests to the
+// routes it's used on, keeping the rest of the current LogPolicy.
+func SampleLogs(rate float64) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			policy := logPolicy(r)
+			policy.Sample = rate
+
+			ctx := context.WithValue(r.Context(), logPolicyKey{}, policy)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+func logPolicy(r *http.Request) LogPolicy {
+	if policy, ok := r.Context().Value(logPolicyKey{}).(LogPolicy); ok {
+		return policy
+	}
+
+	return LogDefaults
+}
+
+// SkipLog reports whether the response to r with the given status should be left
+// out of the logs according to the request's LogPolicy. Errors are never skipped.
+func SkipLog(r *http.Request, status int) bool {
+	if status >= http.StatusBadRequest {
+		return false
+	}
+
+	policy := logPolicy(r)
+
+	if policy.UserAgents != nil && policy.UserAgents.MatchString(r.UserAgent()) {
+		return true
+	}
+
+	for _, pattern := range policy.Paths {
+		if ok, _ := path.Match(pattern, r.URL.Path); ok {
+			return true
+		}
+	}
+
+	if slices.Contains(policy.Statuses, status) {
+		return true
+	}
+
+	return policy.Sample > 0 && policy.Sample < 1 && rand.Float64() >= policy.Sample
+}
@@ -0,0 +1,106 @@
+package requests
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"regexp"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+)
+
+func TestSkipLog(t *testing.T) {
+	skipped := func(policy *LogPolicy, ua, path string, status int) bool {
+		var skip bool
+
+		router := chi.NewRouter()
+		if policy != nil {
+			router.Use(Logging(*policy))
+		}
+		router.Get("/*", func(w http.ResponseWriter, r *http.Request) {
+			skip = SkipLog(r, status)
+		})
+
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("User-Agent", ua)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		return skip
+	}
+
+	t.Run("skips probes and scrapers by default", func(t *testing.T) {
+		if !skipped(nil, "kube-probe/1.29", "/health", http.StatusOK) {
+			t.Error("Expected kube-probe requests to be skipped")
+		}
+
+		if !skipped(nil, "Prometheus/2.51.0", "/metrics", http.StatusOK) {
+			t.Error("Expected prometheus requests to be skipped")
+		}
+
+		if skipped(nil, "Mozilla/5.0", "/books", http.StatusOK) {
+			t.Error("Expected browser requests to be logged")
+		}
+	})
+
+	t.Run("skips paths and statuses", func(t *testing.T) {
+		policy := &LogPolicy{Paths: []string{"/health*"}, Statuses: []int{http.StatusNotModified}}
+
+		if !skipped(policy, "curl/8.0", "/healthz", http.StatusOK) {
+			t.Error("Expected /healthz to be skipped")
+		}
+
+		if !skipped(policy, "curl/8.0", "/books", http.StatusNotModified) {
+			t.Error("Expected 304 responses to be skipped")
+		}
+
+		if skipped(policy, "curl/8.0", "/books", http.StatusOK) {
+			t.Error("Expected /books to be logged")
+		}
+	})
+
+	t.Run("always logs errors", func(t *testing.T) {
+		policy := &LogPolicy{
+			UserAgents: regexp.MustCompile("curl"),
+			Paths:      []string{"/health*"},
+			Statuses:   []int{http.StatusNotFound},
+			Sample:     0.000001,
+		}
+
+		if skipped(policy, "curl/8.0", "/books", http.StatusBadGateway) {
+			t.Error("Expected server errors to be logged")
+		}
+
+		if skipped(policy, "Mozilla/5.0", "/healthz", http.StatusNotFound) {
+			t.Error("Expected client errors to be logged")
+		}
+
+		if skipped(policy, "curl/8.0", "/books", http.StatusMethodNotAllowed) {
+			t.Error("Expected client errors to be logged")
+		}
+	})
+
+	t.Run("samples requests per route", func(t *testing.T) {
+		logged := 0
+
+		router := chi.NewRouter()
+		router.With(SampleLogs(0.25)).Get("/sampled", func(w http.ResponseWriter, r *http.Request) {
+			if !SkipLog(r, http.StatusOK) {
+				logged++
+			}
+		})
+		router.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			if SkipLog(r, http.StatusOK) {
+				t.Error("Expected routes without sampling to be logged")
+			}
+		})
+
+		for range 1000 {
+			router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/sampled", nil))
+		}
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/", nil))
+
+		if logged < 150 || logged > 350 {
+			t.Errorf("Expected about 250 of 1000 requests to be logged, got %d", logged)
+		}
+	})
+}
@@ -1,11 +1,10 @@
 package requests
 
 import (
-	"bytes"
 	"context"
-	"encoding/json"
 	"net/http"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-chi/chi/v5/middleware"
@@ -66,7 +65,12 @@ func AttachLogger(log zerolog.Logger) func(http.Handler) http.Handler {
 	}
 }
 
+// LogBodyLimit is the size of the largest JSON body Log adds to the log entry.
+// Larger bodies are not read.
+var LogBodyLimit int64 = 64 << 10
+
 // Log updates a future log entry with the request parameters such as request ID and headers.
+// JSON bodies are logged too, redacted against the value ReadJSON or Bind decode them into.
 func Log(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		log := zerolog.Ctx(r.Context())
@@ -77,7 +81,7 @@ func Log(next http.Handler) http.Handler {
 			})
 		}
 
-		formattedHeaders := anansi.SimpleMap(r.Header)
+		formattedHeaders := anansi.RedactHeaders(r.Header)
 
 		log.UpdateContext(func(ctx zerolog.Context) zerolog.Context {
 			return ctx.
@@ -88,23 +92,61 @@ func Log(next http.Handler) http.Handler {
 		})
 
 		contentType := r.Header.Get("Content-Type")
-		if strings.Contains(contentType, "application/json") && r.ContentLength > 0 {
+		if strings.Contains(contentType, "application/json") && r.ContentLength > 0 && r.ContentLength <= LogBodyLimit {
 			requestBody, err := ReadBody(r)
 			if err != nil {
-				panic(err)
+				// leave it to the handler to report
+				next.ServeHTTP(w, r)
+				return
 			}
 
-			log.UpdateContext(func(ctx zerolog.Context) zerolog.Context {
-				buffer := new(bytes.Buffer)
-
-				if err := json.Compact(buffer, requestBody); err != nil {
-					panic(err)
-				}
-
-				return ctx.RawJSON("request", buffer.Bytes())
-			})
+			// the body is added to each entry, so it can be redacted against the
+			// type it gets decoded into
+			body := &loggedBody{raw: requestBody}
+			hooked := log.Hook(body)
+			ctx := context.WithValue(hooked.WithContext(r.Context()), loggedBodyKey{}, body)
+			r = r.WithContext(ctx)
 		}
 
 		next.ServeHTTP(w, r)
 	})
 }
+
+type loggedBodyKey struct{}
+
+// loggedBody is a zerolog.Hook that adds the JSON body of a request to log entries,
+// without sensitive values.
+type loggedBody struct {
+	mu       sync.Mutex
+	raw      []byte
+	v        interface{}
+	redacted []byte
+}
+
+func (b *loggedBody) Run(e *zerolog.Event, _ zerolog.Level, _ string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.redacted == nil {
+		// invalid JSON is left for the handler to report
+		redacted, err := anansi.RedactJSON(b.raw, b.v)
+		if err != nil {
+			return
+		}
+		b.redacted = redacted
+	}
+
+	e.RawJSON("request", b.redacted)
+}
+
+// logDecoded redacts the logged body of the request against v, the value it was
+// decoded into, so its `log:"redact"` tags are respected.
+func logDecoded(r *http.Request, v interface{}) {
+	if b, ok := r.Context().Value(loggedBodyKey{}).(*loggedBody); ok {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+
+		b.v = v
+		b.redacted = nil
+	}
+}
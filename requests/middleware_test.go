@@ -132,6 +132,67 @@ func TestLog(t *testing.T) {
 		}
 	})
 
+	t.Run("redacts sensitive headers and fields", func(t *testing.T) {
+		router := chi.NewRouter()
+		logOut := &bytes.Buffer{}
+
+		router.Use(AttachLogger(zerolog.New(logOut)))
+		router.Use(Log)
+		router.Post("/login", func(w http.ResponseWriter, r *http.Request) {
+			log := zerolog.Ctx(r.Context())
+			log.Info().Msg("")
+
+			_, _ = w.Write([]byte(""))
+		})
+
+		req := httptest.NewRequest("POST", "/login", strings.NewReader(`{"email": "ada@example.com", "password": "secret"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer secret")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		if strings.Contains(logOut.String(), "secret") {
+			t.Errorf("Expected secrets to be redacted, got %s", logOut.String())
+		}
+
+		if !strings.Contains(logOut.String(), "ada@example.com") {
+			t.Errorf("Expected the email to be logged, got %s", logOut.String())
+		}
+	})
+
+	t.Run("redacts tagged fields of decoded bodies", func(t *testing.T) {
+		type card struct {
+			Holder string `json:"holder"`
+			Number string `json:"number" log:"redact"`
+		}
+
+		router := chi.NewRouter()
+		logOut := &bytes.Buffer{}
+
+		router.Use(AttachLogger(zerolog.New(logOut)))
+		router.Use(Log)
+		router.Post("/cards", func(w http.ResponseWriter, r *http.Request) {
+			var c card
+			if err := ReadJSON(r, &c); err != nil {
+				t.Fatal(err)
+			}
+
+			log := zerolog.Ctx(r.Context())
+			log.Info().Msg("")
+		})
+
+		req := httptest.NewRequest("POST", "/cards", strings.NewReader(`{"holder": "Ada", "number": "4242424242424242"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		if strings.Contains(logOut.String(), "4242424242424242") {
+			t.Errorf("Expected the card number to be redacted, got %s", logOut.String())
+		}
+
+		if !strings.Contains(logOut.String(), "Ada") {
+			t.Errorf("Expected the holder to be logged, got %s", logOut.String())
+		}
+	})
+
 	t.Run("logs form request", func(t *testing.T) {
 		router := chi.NewRouter()
 		logOut := &bytes.Buffer{}
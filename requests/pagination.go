@@ -0,0 +1,130 @@
+package requests
+
+import (
+	"net/http"
+	"strconv"
+
+	ozzo "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/noxecane/anansi"
+	"github.com/noxecane/anansi/json"
+)
+
+// DefaultLimit is the page size used when the request doesn't set a limit
+var DefaultLimit = 20
+
+var (
+	ErrIntInvalid    = ozzo.NewError("validation_is_int", "must be an integer number")
+	ErrCursorInvalid = ozzo.NewError("validation_cursor_invalid", "must be a valid cursor")
+)
+
+// Page is the position of a page in offset/limit pagination
+type Page struct {
+	Offset int `json:"offset"`
+	Limit  int `json:"limit"`
+}
+
+// PageParams reads the offset and limit query params of the request, using
+// DefaultLimit(or max if it's smaller) when there's no limit. It returns
+// validation.Errors if the offset is negative or the limit is not between 1
+// and max.
+func PageParams(r *http.Request, max int) (Page, error) {
+	query := r.URL.Query()
+	errs := ozzo.Errors{}
+
+	offset, err := intParam(query.Get("offset"), 0)
+	switch {
+	case err != nil:
+		errs["offset"] = err
+	case offset < 0:
+		errs["offset"] = ozzo.ErrMinGreaterEqualThanRequired.SetParams(map[string]interface{}{"threshold": 0})
+	}
+
+	limit, err := limitParam(query.Get("limit"), max)
+	if err != nil {
+		errs["limit"] = err
+	}
+
+	if len(errs) > 0 {
+		return Page{}, errs
+	}
+
+	return Page{Offset: offset, Limit: limit}, nil
+}
+
+// CursorParams reads the limit and cursor query params of the request, decoding
+// the cursor into v when it's set. The limit is read the same way as PageParams.
+// It returns validation.Errors if either of them is invalid.
+func CursorParams(r *http.Request, secret []byte, max int, v any) (int, error) {
+	query := r.URL.Query()
+	errs := ozzo.Errors{}
+
+	limit, err := limitParam(query.Get("limit"), max)
+	if err != nil {
+		errs["limit"] = err
+	}
+
+	if cursor := query.Get("cursor"); cursor != "" {
+		if err := DecodeCursor(secret, cursor, v); err != nil {
+			errs["cursor"] = ErrCursorInvalid
+		}
+	}
+
+	if len(errs) > 0 {
+		return 0, errs
+	}
+
+	return limit, nil
+}
+
+// EncodeCursor converts v to JSON and seals it using anansi.Encrypt so clients
+// can neither read nor forge it.
+func EncodeCursor(secret []byte, v any) (string, error) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return "", err
+	}
+
+	return anansi.Encrypt(secret, raw)
+}
+
+// DecodeCursor opens a cursor created by EncodeCursor into v
+func DecodeCursor(secret []byte, cursor string, v any) error {
+	raw, err := anansi.Decrypt(secret, cursor)
+	if err != nil {
+		return err
+	}
+
+	return json.Unmarshal(raw, v)
+}
+
+func limitParam(raw string, max int) (int, error) {
+	def := DefaultLimit
+	if def > max {
+		def = max
+	}
+
+	limit, err := intParam(raw, def)
+	switch {
+	case err != nil:
+		return 0, err
+	case limit < 1:
+		return 0, ozzo.ErrMinGreaterEqualThanRequired.SetParams(map[string]interface{}{"threshold": 1})
+	case limit > max:
+		return 0, ozzo.ErrMaxLessEqualThanRequired.SetParams(map[string]interface{}{"threshold": max})
+	default:
+		return limit, nil
+	}
+}
+
+func intParam(raw string, def int) (int, error) {
+	if raw == "" {
+		return def, nil
+	}
+
+	n, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, ErrIntInvalid
+	}
+
+	return n, nil
+}
@@ -0,0 +1,97 @@
+package requests
+
+import (
+	"errors"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+
+	ozzo "github.com/go-ozzo/ozzo-validation/v4"
+)
+
+func TestPageParams(t *testing.T) {
+	t.Run("defaults the offset and limit", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/books", nil)
+
+		p, err := PageParams(req, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if p.Offset != 0 || p.Limit != DefaultLimit {
+			t.Errorf("Expected page to be {0 %d}, got %v", DefaultLimit, p)
+		}
+	})
+
+	t.Run("reads the offset and limit", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/books?offset=40&limit=10", nil)
+
+		p, err := PageParams(req, 100)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if p.Offset != 40 || p.Limit != 10 {
+			t.Errorf("Expected page to be {40 10}, got %v", p)
+		}
+	})
+
+	t.Run("enforces the max limit", func(t *testing.T) {
+		req := httptest.NewRequest("GET", "/books?offset=-1&limit=500", nil)
+
+		_, err := PageParams(req, 100)
+
+		var e ozzo.Errors
+		if !errors.As(err, &e) {
+			t.Fatalf("Expected PageParams to fail with validation errors, got %v", err)
+		}
+
+		if e["limit"] == nil || e["offset"] == nil {
+			t.Errorf("Expected both offset and limit to be invalid, got %v", e)
+		}
+	})
+}
+
+func TestCursorParams(t *testing.T) {
+	secret := []byte("ot4EvohHaeSeeshoo1eih7oow0FooWee")
+
+	type position struct {
+		ID int `json:"id"`
+	}
+
+	t.Run("decodes sealed cursors", func(t *testing.T) {
+		cursor, err := EncodeCursor(secret, position{24})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		req := httptest.NewRequest("GET", "/books?limit=5&cursor="+url.QueryEscape(cursor), nil)
+
+		var pos position
+		limit, err := CursorParams(req, secret, 100, &pos)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if limit != 5 {
+			t.Errorf("Expected limit to be 5, got %d", limit)
+		}
+
+		if pos.ID != 24 {
+			t.Errorf("Expected cursor ID to be 24, got %d", pos.ID)
+		}
+	})
+
+	t.Run("rejects forged cursors", func(t *testing.T) {
+		for _, cursor := range []string{"eyJpZCI6MjR9", "forged"} {
+			req := httptest.NewRequest("GET", "/books?cursor="+cursor, nil)
+
+			_, err := CursorParams(req, secret, 100, &position{})
+
+			var e ozzo.Errors
+			if !errors.As(err, &e) || e["cursor"] == nil {
+				t.Errorf("Expected cursor %s to be invalid, got %v", cursor, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,84 @@
+package requests
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"regexp"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/segmentio/ksuid"
+)
+
+var (
+	ErrSlugInvalid = errors.New("slugs can only have lowercase letters, digits, hyphens and underscores")
+	slugRegexp     = regexp.MustCompile(`^[a-z0-9]+(?:[-_][a-z0-9]+)*$`)
+)
+
+// Slug is a URL friendly identifier made up of lowercase letters and digits,
+// separated by hyphens or underscores(like the output of anansi.Slugify).
+type Slug string
+
+func (s *Slug) UnmarshalText(text []byte) error {
+	if !slugRegexp.Match(text) {
+		return ErrSlugInvalid
+	}
+
+	*s = Slug(text)
+	return nil
+}
+
+// ParamError is returned when a URL param can't be parsed into the requested type
+type ParamError struct {
+	Name  string
+	Value string
+	Type  string // readable name of the type, e.g. UUID
+	Err   error
+}
+
+func (e *ParamError) Error() string {
+	return fmt.Sprintf("%s must be a valid %s", e.Name, e.Type)
+}
+
+func (e *ParamError) Unwrap() error { return e.Err }
+
+// Param parses the chi URL param into T. uint64, uuid.UUID, ksuid.KSUID and Slug
+// are supported out of the box, as well as every other type Bind supports.
+// It panics if there's no such param on the route, otherwise it returns a *ParamError
+// if the param can't be parsed.
+func Param[T any](r *http.Request, name string) (T, error) {
+	var v T
+
+	param := chi.URLParam(r, name)
+	if param == "" {
+		panic(fmt.Errorf("requested param %s is not part of route", name))
+	}
+
+	if err := setValue(reflect.ValueOf(&v).Elem(), param); err != nil {
+		return v, &ParamError{Name: name, Value: param, Type: typeName(reflect.TypeOf(v)), Err: err}
+	}
+
+	return v, nil
+}
+
+func typeName(t reflect.Type) string {
+	switch t {
+	case reflect.TypeOf(uuid.UUID{}):
+		return "UUID"
+	case reflect.TypeOf(ksuid.KSUID{}):
+		return "KSUID"
+	case reflect.TypeOf(Slug("")):
+		return "slug"
+	}
+
+	switch t.Kind() {
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "ID"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return "integer"
+	default:
+		return t.String()
+	}
+}
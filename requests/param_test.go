@@ -0,0 +1,100 @@
+package requests
+
+import (
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/google/uuid"
+	"github.com/segmentio/ksuid"
+)
+
+func param[T any](value string) (T, error) {
+	var v T
+	var err error
+
+	router := chi.NewRouter()
+	router.Get("/entities/{id}", func(w http.ResponseWriter, r *http.Request) {
+		v, err = Param[T](r, "id")
+	})
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", "/entities/"+value, nil))
+
+	return v, err
+}
+
+func TestParam(t *testing.T) {
+	t.Run("parses 64-bit IDs", func(t *testing.T) {
+		id, err := param[uint64]("9007199254740993")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id != 9007199254740993 {
+			t.Errorf("Expected the ID to be %d, got %d", uint64(9007199254740993), id)
+		}
+	})
+
+	t.Run("parses UUIDs", func(t *testing.T) {
+		expected := uuid.New()
+
+		id, err := param[uuid.UUID](expected.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id != expected {
+			t.Errorf("Expected the UUID to be %s, got %s", expected, id)
+		}
+	})
+
+	t.Run("parses KSUIDs", func(t *testing.T) {
+		expected := ksuid.New()
+
+		id, err := param[ksuid.KSUID](expected.String())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if id != expected {
+			t.Errorf("Expected the KSUID to be %s, got %s", expected, id)
+		}
+	})
+
+	t.Run("parses slugs", func(t *testing.T) {
+		slug, err := param[Slug]("things-fall_apart-1958")
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if slug != "things-fall_apart-1958" {
+			t.Errorf("Expected the slug to be %s, got %s", "things-fall_apart-1958", slug)
+		}
+	})
+
+	t.Run("fails with a ParamError", func(t *testing.T) {
+		cases := []struct {
+			value string
+			err   func() error
+			name  string
+		}{
+			{"-1", func() error { _, err := param[uint64]("-1"); return err }, "ID"},
+			{"not-a-uuid", func() error { _, err := param[uuid.UUID]("not-a-uuid"); return err }, "UUID"},
+			{"short", func() error { _, err := param[ksuid.KSUID]("short"); return err }, "KSUID"},
+			{"Not_A--Slug", func() error { _, err := param[Slug]("Not_A--Slug"); return err }, "slug"},
+		}
+
+		for _, c := range cases {
+			var paramErr *ParamError
+			if err := c.err(); !errors.As(err, &paramErr) {
+				t.Errorf("Expected a *ParamError for %s, got %v", c.value, err)
+				continue
+			}
+
+			if paramErr.Type != c.name {
+				t.Errorf("Expected the type of %s to be %s, got %s", c.value, c.name, paramErr.Type)
+			}
+		}
+	})
+}
@@ -0,0 +1,249 @@
+package requests
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+	"mime"
+	"net/http"
+	"path/filepath"
+	"strings"
+
+	"github.com/noxecane/anansi/storage"
+	"github.com/segmentio/ksuid"
+)
+
+var (
+	ErrFileTooLarge  = errors.New("file is larger than the limit")
+	ErrFileType      = errors.New("file type is not allowed")
+	ErrTooManyFiles  = errors.New("form has more files than allowed")
+	ErrFieldTooLarge = errors.New("form field is larger than the limit")
+	ErrFormTooLarge  = errors.New("form fields are larger than the limit in total")
+)
+
+// DefaultMaxFieldSize is the size limit of non-file form fields when UploadOptions
+// doesn't set one.
+var DefaultMaxFieldSize int64 = 1 << 20
+
+// DefaultMaxFormSize is the limit on the total size of non-file form fields when
+// UploadOptions doesn't set one.
+var DefaultMaxFormSize int64 = 10 << 20
+
+// UploadOptions are the limits for files in a multipart form.
+type UploadOptions struct {
+	MaxFileSize  int64    // size limit of each file
+	MaxFiles     int      // maximum number of files in the form
+	MaxFieldSize int64    // size limit of each non-file field. Defaults to DefaultMaxFieldSize
+	MaxFormSize  int64    // total size limit of non-file fields, like ParseMultipartForm's maxMemory. Defaults to DefaultMaxFormSize
+	AllowedTypes []string // sniffed content types files can have, e.g. image/png or image/*. Allows all types when empty
+	// Key generates the storage key of a file. Defaults to a KSUID with the extension of the file
+	Key func(field, filename string) string
+}
+
+// File describes a file saved from a multipart form
+type File struct {
+	Field       string `json:"field"`
+	Filename    string `json:"filename"`
+	Key         string `json:"key"`
+	ContentType string `json:"content_type"` // sniffed from the first 512 bytes of the file
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+}
+
+// FileError is returned for files that break UploadOptions
+type FileError struct {
+	Field    string
+	Filename string
+	Err      error
+}
+
+func (e *FileError) Error() string {
+	return fmt.Sprintf("%s(%s): %v", e.Field, e.Filename, e.Err)
+}
+
+func (e *FileError) Unwrap() error { return e.Err }
+
+// Upload streams the files in a multipart form to the store part by part, rather
+// than buffering the form like MultipartFormData. The size and content type of each
+// file are checked and its checksum computed while it's being saved. The rest of the
+// fields are bound to v(if it's not nil) the same way MultipartFormData does.
+//
+// Files that break the options fail with a *FileError. Every file saved is deleted
+// if the upload fails for any reason, including validation of v.
+func Upload(r *http.Request, store storage.Storage, opts UploadOptions, v interface{}) ([]File, error) {
+	ctx := r.Context()
+
+	LimitBody(r)
+	reader, err := r.MultipartReader()
+	if err != nil {
+		return nil, err
+	}
+
+	if opts.MaxFieldSize == 0 {
+		opts.MaxFieldSize = DefaultMaxFieldSize
+	}
+
+	if opts.MaxFormSize == 0 {
+		opts.MaxFormSize = DefaultMaxFormSize
+	}
+
+	if opts.Key == nil {
+		opts.Key = func(_, filename string) string {
+			return ksuid.New().String() + strings.ToLower(filepath.Ext(filename))
+		}
+	}
+
+	var files []File
+	values := make(map[string][]string)
+	formLeft := opts.MaxFormSize
+
+	fail := func(err error) ([]File, error) {
+		for _, f := range files {
+			_ = store.Delete(ctx, f.Key)
+		}
+
+		return nil, err
+	}
+
+	for {
+		part, err := reader.NextPart()
+		if err == io.EOF {
+			break
+		}
+
+		if err != nil {
+			return fail(err)
+		}
+
+		field := part.FormName()
+		if part.FileName() == "" {
+			limit := min(opts.MaxFieldSize, formLeft)
+			raw, err := io.ReadAll(io.LimitReader(part, limit+1))
+			if err != nil {
+				return fail(err)
+			}
+
+			switch {
+			case int64(len(raw)) > opts.MaxFieldSize:
+				return fail(fmt.Errorf("%s: %w", field, ErrFieldTooLarge))
+			case int64(len(raw)) > formLeft:
+				return fail(ErrFormTooLarge)
+			}
+
+			formLeft -= int64(len(raw))
+			values[field] = append(values[field], string(raw))
+			continue
+		}
+
+		if opts.MaxFiles > 0 && len(files) == opts.MaxFiles {
+			return fail(ErrTooManyFiles)
+		}
+
+		f, err := saveFile(r, store, opts, field, part.FileName(), part)
+		if err != nil {
+			return fail(err)
+		}
+
+		files = append(files, f)
+	}
+
+	if v != nil {
+		if err := parseParams(ctx, values, v); err != nil {
+			return fail(err)
+		}
+	}
+
+	return files, nil
+}
+
+func saveFile(r *http.Request, store storage.Storage, opts UploadOptions, field, filename string, part io.Reader) (File, error) {
+	fileErr := func(err error) error {
+		return &FileError{Field: field, Filename: filename, Err: err}
+	}
+
+	// sniff the content type rather than trust the client
+	head := make([]byte, 512)
+	n, err := io.ReadFull(part, head)
+	if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+		return File{}, err
+	}
+	head = head[:n]
+
+	contentType := http.DetectContentType(head)
+	if !allowedType(contentType, opts.AllowedTypes) {
+		return File{}, fileErr(ErrFileType)
+	}
+
+	checksum := sha256.New()
+	body := &uploadReader{
+		r:     io.MultiReader(bytes.NewReader(head), part),
+		hash:  checksum,
+		limit: opts.MaxFileSize,
+	}
+
+	key := opts.Key(field, filename)
+	size, err := store.Save(r.Context(), key, body)
+	if err != nil {
+		if errors.Is(err, ErrFileTooLarge) {
+			return File{}, fileErr(ErrFileTooLarge)
+		}
+
+		return File{}, err
+	}
+
+	return File{
+		Field:       field,
+		Filename:    filepath.Base(filename),
+		Key:         key,
+		ContentType: contentType,
+		Size:        size,
+		SHA256:      hex.EncodeToString(checksum.Sum(nil)),
+	}, nil
+}
+
+func allowedType(contentType string, allowed []string) bool {
+	if len(allowed) == 0 {
+		return true
+	}
+
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+
+	for _, a := range allowed {
+		if prefix, ok := strings.CutSuffix(a, "/*"); ok {
+			if strings.HasPrefix(mediaType, prefix+"/") {
+				return true
+			}
+		} else if mediaType == a {
+			return true
+		}
+	}
+
+	return false
+}
+
+// uploadReader hashes the file as it's read, failing once it goes over the limit
+type uploadReader struct {
+	r     io.Reader
+	hash  hash.Hash
+	limit int64
+	read  int64
+}
+
+func (u *uploadReader) Read(buf []byte) (int, error) {
+	n, err := u.r.Read(buf)
+	u.read += int64(n)
+	u.hash.Write(buf[:n])
+
+	if u.limit > 0 && u.read > u.limit {
+		return n, ErrFileTooLarge
+	}
+
+	return n, err
+}
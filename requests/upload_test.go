@@ -0,0 +1,218 @@
+package requests
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	ozzo "github.com/go-ozzo/ozzo-validation/v4"
+	"github.com/noxecane/anansi/storage"
+)
+
+var pngHeader = []byte("\x89PNG\r\n\x1a\n")
+
+type uploadPart struct {
+	field, filename string
+	content         []byte
+}
+
+func newUpload(t *testing.T, parts ...uploadPart) *http.Request {
+	body := new(bytes.Buffer)
+	w := multipart.NewWriter(body)
+
+	for _, p := range parts {
+		var pw io.Writer
+		var err error
+		if p.filename == "" {
+			pw, err = w.CreateFormField(p.field)
+		} else {
+			pw, err = w.CreateFormFile(p.field, p.filename)
+		}
+
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if _, err := pw.Write(p.content); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("POST", "/", body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+
+	return req
+}
+
+type album struct {
+	Title string `json:"title" mod:"trim"`
+}
+
+func (a *album) Validate() error {
+	return ozzo.ValidateStruct(a,
+		ozzo.Field(&a.Title, ozzo.Required),
+	)
+}
+
+func TestUpload(t *testing.T) {
+	ctx := context.TODO()
+	image := append(pngHeader, bytes.Repeat([]byte("x"), 1000)...)
+	opts := UploadOptions{MaxFileSize: 2048, AllowedTypes: []string{"image/*"}}
+
+	t.Run("saves files and binds fields", func(t *testing.T) {
+		store := storage.NewMemory()
+		req := newUpload(t,
+			uploadPart{field: "cover", filename: "cover.PNG", content: image},
+			uploadPart{field: "title", content: []byte("  Things fall apart ")},
+		)
+
+		var a album
+		files, err := Upload(req, store, opts, &a)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if a.Title != "Things fall apart" {
+			t.Errorf("Expected the title to be %s, got %s", "Things fall apart", a.Title)
+		}
+
+		if len(files) != 1 {
+			t.Fatalf("Expected %d file, got %d", 1, len(files))
+		}
+
+		f := files[0]
+		sum := sha256.Sum256(image)
+
+		if f.SHA256 != hex.EncodeToString(sum[:]) {
+			t.Errorf("Expected the checksum to be %x, got %s", sum, f.SHA256)
+		}
+
+		if f.ContentType != "image/png" {
+			t.Errorf("Expected the content type to be %s, got %s", "image/png", f.ContentType)
+		}
+
+		if f.Size != int64(len(image)) {
+			t.Errorf("Expected the size to be %d, got %d", len(image), f.Size)
+		}
+
+		rc, err := store.Open(ctx, f.Key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer rc.Close()
+
+		saved, _ := io.ReadAll(rc)
+		if !bytes.Equal(saved, image) {
+			t.Error("Expected the saved file to match the upload")
+		}
+	})
+
+	t.Run("rejects files over the size limit", func(t *testing.T) {
+		store := storage.NewMemory()
+		req := newUpload(t,
+			uploadPart{field: "cover", filename: "cover.png", content: append(pngHeader, bytes.Repeat([]byte("x"), 4096)...)},
+		)
+
+		var fileErr *FileError
+		_, err := Upload(req, store, opts, nil)
+		if !errors.As(err, &fileErr) || fileErr.Err != ErrFileTooLarge {
+			t.Errorf("Expected ErrFileTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("caps the body to the decoding limit", func(t *testing.T) {
+		store := storage.NewMemory()
+		req := newUpload(t,
+			uploadPart{field: "cover", filename: "cover.png", content: image},
+		)
+		req = req.WithContext(context.WithValue(req.Context(), decodeKey{}, DecodeOptions{MaxBytes: 512}))
+
+		var tooLarge *http.MaxBytesError
+		_, err := Upload(req, store, opts, nil)
+		if !errors.As(err, &tooLarge) {
+			t.Errorf("Expected a *http.MaxBytesError, got %v", err)
+		}
+	})
+
+	t.Run("limits the total size of fields", func(t *testing.T) {
+		store := storage.NewMemory()
+		field := bytes.Repeat([]byte("x"), 100)
+		req := newUpload(t,
+			uploadPart{field: "tags", content: field},
+			uploadPart{field: "tags", content: field},
+			uploadPart{field: "tags", content: field},
+		)
+
+		limited := opts
+		limited.MaxFieldSize = 150
+		limited.MaxFormSize = 250
+
+		_, err := Upload(req, store, limited, nil)
+		if err != ErrFormTooLarge {
+			t.Errorf("Expected ErrFormTooLarge, got %v", err)
+		}
+	})
+
+	t.Run("rejects files by their sniffed content type", func(t *testing.T) {
+		req := newUpload(t,
+			uploadPart{field: "cover", filename: "cover.png", content: []byte("<html><body>not an image</body></html>")},
+		)
+
+		var fileErr *FileError
+		_, err := Upload(req, storage.NewMemory(), opts, nil)
+		if !errors.As(err, &fileErr) || fileErr.Err != ErrFileType {
+			t.Errorf("Expected ErrFileType, got %v", err)
+		}
+	})
+
+	t.Run("deletes saved files when the upload fails", func(t *testing.T) {
+		store := storage.NewMemory()
+		keys := []string{}
+		withKeys := opts
+		withKeys.Key = func(field, _ string) string {
+			keys = append(keys, field)
+			return field
+		}
+
+		req := newUpload(t,
+			uploadPart{field: "cover", filename: "cover.png", content: image},
+			uploadPart{field: "title", content: []byte("   ")},
+		)
+
+		var a album
+		if _, err := Upload(req, store, withKeys, &a); err == nil {
+			t.Fatal("Expected the upload to fail validation")
+		}
+
+		for _, key := range keys {
+			if _, err := store.Open(ctx, key); err != storage.ErrNotFound {
+				t.Errorf("Expected %s to be deleted, got %v", key, err)
+			}
+		}
+	})
+
+	t.Run("limits the number of files", func(t *testing.T) {
+		req := newUpload(t,
+			uploadPart{field: "cover", filename: "cover.png", content: image},
+			uploadPart{field: "back", filename: "back.png", content: image},
+		)
+
+		withMax := opts
+		withMax.MaxFiles = 1
+
+		if _, err := Upload(req, storage.NewMemory(), withMax, nil); !errors.Is(err, ErrTooManyFiles) {
+			t.Errorf("Expected ErrTooManyFiles, got %v", err)
+		}
+	})
+}
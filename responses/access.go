@@ -0,0 +1,102 @@
+package responses
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/noxecane/anansi"
+	"github.com/noxecane/anansi/requests"
+	"github.com/rs/zerolog"
+)
+
+type accessKey struct{}
+
+type accessEntry struct {
+	err error
+}
+
+// AccessLog creates a middleware that logs exactly one line when a request completes,
+// however the response was written, with its status, size, duration, route pattern and
+// the fields already on the request's logger. user identifies the user or session
+// behind the request, use nil to leave it out of the logs.
+//
+// Responders that know about the access log(api and html) leave their own completion
+// lines out and pass their errors to it with LogError. Requests that failed are always
+// logged, others go through requests.SkipLog. Make sure it's used after requests.Log
+// and before api.Recoverer.
+func AccessLog(user func(*http.Request) string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			start := time.Now()
+			entry := &accessEntry{}
+
+			tw, ok := w.(TimedResponseWriter)
+			if !ok {
+				tw = newWriter(w, r.ProtoMajor)
+			}
+			r = r.WithContext(context.WithValue(r.Context(), accessKey{}, entry))
+
+			// log requests that end in a panic as well
+			defer func() {
+				status := tw.Code()
+				if status == 0 {
+					// net/http sends a 200 for handlers that don't write
+					status = http.StatusOK
+				}
+
+				if entry.err == nil && requests.SkipLog(r, status) {
+					return
+				}
+
+				log := zerolog.Ctx(r.Context())
+				event := log.Info()
+				if entry.err != nil {
+					event = log.Err(entry.err)
+				} else if status >= http.StatusInternalServerError {
+					event = log.Error()
+				}
+
+				if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+					event = event.Str("route", rctx.RoutePattern())
+				}
+
+				if user != nil {
+					if id := user(r); id != "" {
+						event = event.Str("user_id", id)
+					}
+				}
+
+				if sw, ok := tw.(SizedResponseWriter); ok {
+					event = event.Int("length", sw.Size())
+				}
+
+				event.
+					Int("status", status).
+					Dur("duration", time.Since(start)).
+					Interface("response_headers", anansi.RedactHeaders(tw.Header())).
+					Msg("")
+			}()
+
+			next.ServeHTTP(tw, r)
+		})
+	}
+}
+
+// AccessLogged reports whether AccessLog logs the completion of r.
+func AccessLogged(r *http.Request) bool {
+	_, ok := r.Context().Value(accessKey{}).(*accessEntry)
+	return ok
+}
+
+// LogError hands err to the AccessLog of r so it's included in the completion line,
+// returning false if r has no access log.
+func LogError(r *http.Request, err error) bool {
+	entry, ok := r.Context().Value(accessKey{}).(*accessEntry)
+	if ok {
+		entry.err = err
+	}
+
+	return ok
+}
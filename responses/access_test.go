@@ -0,0 +1,145 @@
+package responses
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/noxecane/anansi/json"
+	"github.com/noxecane/anansi/requests"
+	"github.com/rs/zerolog"
+)
+
+type accessLine struct {
+	Level    string  `json:"level"`
+	Method   string  `json:"method"`
+	Route    string  `json:"route"`
+	UserID   string  `json:"user_id"`
+	Status   int     `json:"status"`
+	Length   int     `json:"length"`
+	Duration float64 `json:"duration"`
+	Error    string  `json:"error"`
+}
+
+func TestAccessLog(t *testing.T) {
+	logOut := &bytes.Buffer{}
+
+	router := chi.NewRouter()
+	router.Use(requests.AttachLogger(zerolog.New(logOut)))
+	router.Use(requests.Log)
+	router.Use(AccessLog(func(r *http.Request) string {
+		return r.Header.Get("X-User")
+	}))
+
+	router.Get("/files/{name}", func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte("hello world"))
+	})
+	router.Get("/old", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "/new", http.StatusMovedPermanently)
+	})
+	router.Get("/failed", func(w http.ResponseWriter, r *http.Request) {
+		LogError(r, errors.New("could not find the book"))
+		http.Error(w, "not found", http.StatusNotFound)
+	})
+
+	lines := func(t *testing.T, path, ua string) []accessLine {
+		logOut.Reset()
+
+		req := httptest.NewRequest("GET", path, nil)
+		req.Header.Set("X-User", "usr_1")
+		req.Header.Set("User-Agent", ua)
+		router.ServeHTTP(httptest.NewRecorder(), req)
+
+		var entries []accessLine
+		for _, l := range strings.Split(strings.TrimSpace(logOut.String()), "\n") {
+			if l == "" {
+				continue
+			}
+
+			var entry accessLine
+			if err := json.Unmarshal([]byte(l), &entry); err != nil {
+				t.Fatal(err)
+			}
+			entries = append(entries, entry)
+		}
+
+		return entries
+	}
+
+	t.Run("logs one line for plain writes", func(t *testing.T) {
+		entries := lines(t, "/files/hello.txt", "curl/8.0")
+		if len(entries) != 1 {
+			t.Fatalf("Expected one log line, got %d", len(entries))
+		}
+
+		entry := entries[0]
+		if entry.Status != http.StatusOK {
+			t.Errorf("Expected status to be %d, got %d", http.StatusOK, entry.Status)
+		}
+
+		if entry.Length != len("hello world") {
+			t.Errorf("Expected length to be %d, got %d", len("hello world"), entry.Length)
+		}
+
+		if entry.Route != "/files/{name}" {
+			t.Errorf("Expected route to be /files/{name}, got %s", entry.Route)
+		}
+
+		if entry.UserID != "usr_1" {
+			t.Errorf("Expected user_id to be usr_1, got %s", entry.UserID)
+		}
+
+		if entry.Method != "GET" {
+			t.Errorf("Expected the request fields to be logged, got method %q", entry.Method)
+		}
+	})
+
+	t.Run("logs redirects", func(t *testing.T) {
+		entries := lines(t, "/old", "curl/8.0")
+		if len(entries) != 1 {
+			t.Fatalf("Expected one log line, got %d", len(entries))
+		}
+
+		if entries[0].Status != http.StatusMovedPermanently {
+			t.Errorf("Expected status to be %d, got %d", http.StatusMovedPermanently, entries[0].Status)
+		}
+	})
+
+	t.Run("skips probes", func(t *testing.T) {
+		entries := lines(t, "/files/hello.txt", "kube-probe/1.29")
+		if len(entries) != 0 {
+			t.Errorf("Expected no log lines, got %d", len(entries))
+		}
+	})
+
+	t.Run("always logs errors", func(t *testing.T) {
+		entries := lines(t, "/failed", "kube-probe/1.29")
+		if len(entries) != 1 {
+			t.Fatalf("Expected one log line, got %d", len(entries))
+		}
+
+		if entries[0].Level != "error" || entries[0].Error != "could not find the book" {
+			t.Errorf("Expected the error to be logged, got %+v", entries[0])
+		}
+	})
+
+	t.Run("shares the timed writer with ResponseTime", func(t *testing.T) {
+		timed := chi.NewRouter()
+		timed.Use(AccessLog(nil))
+		timed.Use(ResponseTime)
+		timed.Get("/", func(w http.ResponseWriter, r *http.Request) {
+			_, _ = w.Write([]byte("hello world"))
+		})
+
+		res := httptest.NewRecorder()
+		timed.ServeHTTP(res, httptest.NewRequest("GET", "/", nil))
+
+		if n := len(res.Header().Values(ResponseTimeHeader)); n != 1 {
+			t.Errorf("Expected one %s header, got %d", ResponseTimeHeader, n)
+		}
+	})
+}
@@ -9,8 +9,14 @@ import (
 
 // ResponseTime adds a "X-Response-Time" header once the handler writes the header
 // of the response. Ensure to Use this middleware before any middleware that Write.
+// It does nothing if the writer is already a TimedResponseWriter(e.g. from AccessLog).
 func ResponseTime(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, ok := w.(TimedResponseWriter); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
 		ww := newWriter(w, r.ProtoMajor)
 		next.ServeHTTP(ww, r)
 	})
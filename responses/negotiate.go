@@ -0,0 +1,327 @@
+package responses
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/csv"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/noxecane/anansi/json"
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+var (
+	ErrNotAcceptable = errors.New("none of the accepted media types can represent the response")
+	ErrNotCSV        = errors.New("only slices of structs or maps can be encoded as CSV")
+)
+
+// Encoder marshals response values into a media type
+type Encoder struct {
+	ContentType string // value of the Content-Type header
+	Marshal     func(v any) ([]byte, error)
+}
+
+var (
+	encodersMu sync.RWMutex
+	encoders   = map[string]Encoder{
+		"application/json": {
+			ContentType: "application/json; charset=utf-8",
+			Marshal:     json.Marshal,
+		},
+		"application/msgpack": {
+			ContentType: "application/msgpack",
+			Marshal:     marshalMsgpack,
+		},
+		"text/csv": {
+			ContentType: "text/csv; charset=utf-8",
+			Marshal:     marshalCSV,
+		},
+	}
+	// order of preference for wildcards, JSON always comes first
+	mediaTypes = []string{"application/json", "application/msgpack", "text/csv"}
+)
+
+// XML encodes responses with encoding/xml, wrapping slices in an <items> element so
+// the document has a single root. It isn't registered by default since it ignores json
+// tags(and browsers accept XML), so only register it for responses that have xml tags.
+//
+//	responses.RegisterEncoder("application/xml", responses.XML)
+var XML = Encoder{
+	ContentType: "application/xml; charset=utf-8",
+	Marshal:     marshalXML,
+}
+
+// RegisterEncoder adds an encoder for the media type, replacing the existing one if
+// there's any. Register encoders before the server starts.
+func RegisterEncoder(mediaType string, enc Encoder) {
+	encodersMu.Lock()
+	defer encodersMu.Unlock()
+
+	mediaType = strings.ToLower(mediaType)
+	if _, ok := encoders[mediaType]; !ok {
+		mediaTypes = append(mediaTypes, mediaType)
+	}
+	encoders[mediaType] = enc
+}
+
+// Negotiate encodes v in the media type the client prefers according to the Accept
+// header, returning the content type for the response. It falls through to the next
+// preferred media type if v can't be encoded in one(e.g. CSV for non-slices), and
+// returns ErrNotAcceptable if none works. An empty Accept header gets JSON.
+func Negotiate(accept string, v any) (string, []byte, error) {
+	encodersMu.RLock()
+	defer encodersMu.RUnlock()
+
+	if strings.TrimSpace(accept) == "" {
+		accept = "application/json"
+	}
+
+	for _, mediaType := range acceptable(accept) {
+		enc := encoders[mediaType]
+
+		raw, err := enc.Marshal(v)
+		if err == nil {
+			return enc.ContentType, raw, nil
+		}
+	}
+
+	return "", nil, ErrNotAcceptable
+}
+
+type mediaRange struct {
+	mediaType string
+	q         float64
+}
+
+// acceptable lists the registered media types matching the Accept header, in the
+// order the client prefers them. Media types the client refuses with q=0 are left out,
+// unless a more specific range accepts them.
+func acceptable(accept string) []string {
+	var ranges, refused []mediaRange
+	for _, part := range strings.Split(accept, ",") {
+		params := strings.Split(part, ";")
+		mr := mediaRange{mediaType: strings.ToLower(strings.TrimSpace(params[0])), q: 1}
+
+		for _, param := range params[1:] {
+			k, v, _ := strings.Cut(strings.TrimSpace(param), "=")
+			if k == "q" {
+				if q, err := strconv.ParseFloat(v, 64); err == nil {
+					mr.q = q
+				}
+			}
+		}
+
+		if mr.mediaType == "" {
+			continue
+		}
+
+		if mr.q > 0 {
+			ranges = append(ranges, mr)
+		} else {
+			refused = append(refused, mr)
+		}
+	}
+
+	// more specific ranges win ties
+	sort.SliceStable(ranges, func(i, j int) bool {
+		if ranges[i].q != ranges[j].q {
+			return ranges[i].q > ranges[j].q
+		}
+		return specificity(ranges[i].mediaType) > specificity(ranges[j].mediaType)
+	})
+
+	var matched []string
+	seen := make(map[string]bool)
+	for _, mr := range ranges {
+		for _, mediaType := range mediaTypes {
+			if !seen[mediaType] && matchMedia(mr.mediaType, mediaType) && !refuses(refused, mr, mediaType) {
+				seen[mediaType] = true
+				matched = append(matched, mediaType)
+			}
+		}
+	}
+
+	return matched
+}
+
+// refuses checks if a range in refused that's more specific than mr matches mediaType
+func refuses(refused []mediaRange, mr mediaRange, mediaType string) bool {
+	for _, r := range refused {
+		if matchMedia(r.mediaType, mediaType) && specificity(r.mediaType) > specificity(mr.mediaType) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func specificity(mediaRange string) int {
+	switch {
+	case mediaRange == "*/*":
+		return 0
+	case strings.HasSuffix(mediaRange, "/*"):
+		return 1
+	default:
+		return 2
+	}
+}
+
+func matchMedia(mediaRange, mediaType string) bool {
+	if mediaRange == "*/*" || mediaRange == mediaType {
+		return true
+	}
+
+	prefix, ok := strings.CutSuffix(mediaRange, "/*")
+	return ok && strings.HasPrefix(mediaType, prefix+"/")
+}
+
+func marshalXML(v any) ([]byte, error) {
+	val := reflect.ValueOf(v)
+	if (val.Kind() == reflect.Slice || val.Kind() == reflect.Array) && val.Type().Elem().Kind() != reflect.Uint8 {
+		return xml.Marshal(struct {
+			XMLName xml.Name `xml:"items"`
+			Items   any      `xml:"item"`
+		}{Items: v})
+	}
+
+	return xml.Marshal(v)
+}
+
+func marshalMsgpack(v any) ([]byte, error) {
+	var buf bytes.Buffer
+
+	enc := msgpack.NewEncoder(&buf)
+	// use the same keys as JSON responses
+	enc.SetCustomStructTag("json")
+
+	if err := enc.Encode(v); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+// marshalCSV writes a header row from the JSON names of struct fields(or sorted map
+// keys) followed by a row for each item of the slice.
+func marshalCSV(v any) ([]byte, error) {
+	val := reflect.ValueOf(v)
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		return nil, ErrNotCSV
+	}
+
+	elem := val.Type().Elem()
+	for elem.Kind() == reflect.Pointer {
+		elem = elem.Elem()
+	}
+
+	var header []string
+	var row func(item reflect.Value) []string
+
+	switch elem.Kind() {
+	case reflect.Struct:
+		var fields []int
+		for i := 0; i < elem.NumField(); i++ {
+			f := elem.Field(i)
+			name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+			if !f.IsExported() || name == "-" {
+				continue
+			}
+
+			if name == "" {
+				name = f.Name
+			}
+			header = append(header, name)
+			fields = append(fields, i)
+		}
+
+		row = func(item reflect.Value) []string {
+			record := make([]string, len(fields))
+			for i, f := range fields {
+				record[i] = csvValue(item.Field(f))
+			}
+			return record
+		}
+	case reflect.Map:
+		if elem.Key().Kind() != reflect.String {
+			return nil, ErrNotCSV
+		}
+
+		if val.Len() > 0 {
+			first := reflect.Indirect(val.Index(0))
+			for _, k := range first.MapKeys() {
+				header = append(header, k.String())
+			}
+			sort.Strings(header)
+		}
+
+		row = func(item reflect.Value) []string {
+			record := make([]string, len(header))
+			for i, k := range header {
+				record[i] = csvValue(item.MapIndex(reflect.ValueOf(k).Convert(elem.Key())))
+			}
+			return record
+		}
+	default:
+		return nil, ErrNotCSV
+	}
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	if err := w.Write(header); err != nil {
+		return nil, err
+	}
+
+	for i := 0; i < val.Len(); i++ {
+		item := val.Index(i)
+		for item.Kind() == reflect.Pointer {
+			item = item.Elem()
+		}
+
+		if !item.IsValid() {
+			continue
+		}
+
+		if err := w.Write(row(item)); err != nil {
+			return nil, err
+		}
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
+
+func csvValue(v reflect.Value) string {
+	for v.IsValid() && (v.Kind() == reflect.Pointer || v.Kind() == reflect.Interface) {
+		if v.IsNil() {
+			return ""
+		}
+		v = v.Elem()
+	}
+
+	if !v.IsValid() {
+		return ""
+	}
+
+	if tm, ok := v.Interface().(encoding.TextMarshaler); ok {
+		text, err := tm.MarshalText()
+		if err == nil {
+			return string(text)
+		}
+	}
+
+	switch v.Kind() {
+	case reflect.Struct, reflect.Map, reflect.Slice, reflect.Array:
+		raw, _ := json.Marshal(v.Interface())
+		return string(raw)
+	default:
+		return fmt.Sprint(v.Interface())
+	}
+}
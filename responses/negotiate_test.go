@@ -0,0 +1,173 @@
+package responses
+
+import (
+	"encoding/xml"
+	"testing"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+type book struct {
+	Title     string    `json:"title"`
+	Pages     *int      `json:"pages"`
+	Published time.Time `json:"published_at"`
+	Secret    string    `json:"-"`
+}
+
+func TestNegotiate(t *testing.T) {
+	pages := 209
+	published := time.Date(1958, 6, 17, 0, 0, 0, 0, time.UTC)
+	books := []book{{Title: "Things fall apart", Pages: &pages, Published: published, Secret: "okonkwo"}, {Title: "Arrow of God"}}
+
+	t.Run("defaults to JSON", func(t *testing.T) {
+		for _, accept := range []string{"", "*/*", "application/*"} {
+			ct, _, err := Negotiate(accept, books)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ct != "application/json; charset=utf-8" {
+				t.Errorf("Expected the content type for %q to be JSON, got %s", accept, ct)
+			}
+		}
+	})
+
+	t.Run("prefers media types with higher quality", func(t *testing.T) {
+		ct, _, err := Negotiate("application/json;q=0.2, application/msgpack;q=0.8, text/*;q=0.5", books)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ct != "application/msgpack" {
+			t.Errorf("Expected the content type to be %s, got %s", "application/msgpack", ct)
+		}
+	})
+
+	t.Run("encodes msgpack with JSON names", func(t *testing.T) {
+		_, raw, err := Negotiate("application/msgpack", books[0])
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		var m map[string]interface{}
+		if err := msgpack.Unmarshal(raw, &m); err != nil {
+			t.Fatal(err)
+		}
+
+		if m["title"] != books[0].Title {
+			t.Errorf("Expected the title to be %s, got %v", books[0].Title, m["title"])
+		}
+	})
+
+	t.Run("encodes slices as CSV", func(t *testing.T) {
+		_, raw, err := Negotiate("text/csv", books)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "title,pages,published_at\n" +
+			"Things fall apart,209,1958-06-17T00:00:00Z\n" +
+			"Arrow of God,,0001-01-01T00:00:00Z\n"
+		if string(raw) != expected {
+			t.Errorf("Expected the CSV to be %q, got %q", expected, raw)
+		}
+
+		_, raw, err = Negotiate("text/csv", []map[string]int{{"b": 2, "a": 1}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if string(raw) != "a,b\n1,2\n" {
+			t.Errorf("Expected the CSV to be %q, got %q", "a,b\n1,2\n", raw)
+		}
+	})
+
+	t.Run("leaves out media types refused with q=0", func(t *testing.T) {
+		ct, _, err := Negotiate("application/json;q=0, */*;q=0.5", books)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ct != "application/msgpack" {
+			t.Errorf("Expected the content type to be %s, got %s", "application/msgpack", ct)
+		}
+
+		ct, _, err = Negotiate("text/*;q=0, text/csv, */*;q=0.5", books)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ct != "text/csv; charset=utf-8" {
+			t.Errorf("Expected the content type to be %s, got %s", "text/csv; charset=utf-8", ct)
+		}
+
+		if _, _, err := Negotiate("application/json;q=0", books); err != ErrNotAcceptable {
+			t.Errorf("Expected ErrNotAcceptable when JSON is refused, got %v", err)
+		}
+	})
+
+	t.Run("returns ErrNotAcceptable when nothing matches", func(t *testing.T) {
+		if _, _, err := Negotiate("text/csv", books[0]); err != ErrNotAcceptable {
+			t.Errorf("Expected ErrNotAcceptable for CSV of a struct, got %v", err)
+		}
+
+		if _, _, err := Negotiate("image/png", books); err != ErrNotAcceptable {
+			t.Errorf("Expected ErrNotAcceptable for unknown media types, got %v", err)
+		}
+	})
+
+	t.Run("leaves XML out by default", func(t *testing.T) {
+		browser := "text/html,application/xhtml+xml,application/xml;q=0.9,*/*;q=0.8"
+
+		ct, _, err := Negotiate(browser, books)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ct != "application/json; charset=utf-8" {
+			t.Errorf("Expected browsers to get JSON, got %s", ct)
+		}
+	})
+
+	t.Run("wraps slices in a root element for XML", func(t *testing.T) {
+		type item struct {
+			Title string `xml:"title"`
+		}
+
+		raw, err := XML.Marshal([]item{{"Things fall apart"}, {"Arrow of God"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "<items><item><title>Things fall apart</title></item><item><title>Arrow of God</title></item></items>"
+		if string(raw) != expected {
+			t.Errorf("Expected the XML to be %s, got %s", expected, raw)
+		}
+
+		var decoded struct {
+			Items []item `xml:"item"`
+		}
+		if err := xml.Unmarshal(raw, &decoded); err != nil || len(decoded.Items) != 2 {
+			t.Errorf("Expected the XML to round-trip, got %v %v", decoded.Items, err)
+		}
+	})
+
+	t.Run("uses registered encoders", func(t *testing.T) {
+		RegisterEncoder("text/plain", Encoder{
+			ContentType: "text/plain; charset=utf-8",
+			Marshal: func(v any) ([]byte, error) {
+				return []byte("plain"), nil
+			},
+		})
+
+		ct, raw, err := Negotiate("text/plain", books)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if ct != "text/plain; charset=utf-8" || string(raw) != "plain" {
+			t.Errorf("Expected the registered encoder to be used, got %s %s", ct, raw)
+		}
+	})
+}
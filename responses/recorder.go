@@ -0,0 +1,97 @@
+package responses
+
+import (
+	"bytes"
+	"net/http"
+)
+
+// RecordingResponseWriter is a wrapper around the http.ResponseWriter that keeps
+// a copy of everything written to the response, so it can be replayed later.
+type RecordingResponseWriter interface {
+	http.ResponseWriter
+	// Code returns the status code written, or 200 for handlers that didn't write
+	// one as that's what net/http sends.
+	Code() int
+	// Headers returns the headers as they were when the header was written, or the
+	// current headers if it hasn't been written.
+	Headers() http.Header
+	Body() []byte
+}
+
+type recordingWriter struct {
+	http.ResponseWriter
+	code        int
+	header      http.Header
+	body        bytes.Buffer
+	wroteHeader bool
+}
+
+type recordingFlushWriter struct {
+	*recordingWriter
+}
+
+// Record wraps the response writer to record the status, headers and body written
+// by a handler. The response still gets written to w as usual.
+func Record(w http.ResponseWriter) RecordingResponseWriter {
+	rw := &recordingWriter{ResponseWriter: w}
+	if _, ok := w.(http.Flusher); ok {
+		return &recordingFlushWriter{rw}
+	}
+
+	return rw
+}
+
+func (rw *recordingWriter) WriteHeader(code int) {
+	if !rw.wroteHeader {
+		rw.wroteHeader = true
+		rw.code = code
+		rw.header = rw.Header().Clone()
+
+		rw.ResponseWriter.WriteHeader(code)
+	}
+}
+
+func (rw *recordingWriter) Write(buf []byte) (int, error) {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	rw.body.Write(buf)
+	return rw.ResponseWriter.Write(buf)
+}
+
+func (rw *recordingWriter) Code() int {
+	if !rw.wroteHeader {
+		return http.StatusOK
+	}
+
+	return rw.code
+}
+
+func (rw *recordingWriter) Headers() http.Header {
+	if !rw.wroteHeader {
+		return rw.Header().Clone()
+	}
+
+	return rw.header
+}
+
+func (rw *recordingWriter) Body() []byte {
+	return rw.body.Bytes()
+}
+
+// Unwrap lets http.ResponseController reach the original writer
+func (rw *recordingWriter) Unwrap() http.ResponseWriter {
+	return rw.ResponseWriter
+}
+
+func (rw *recordingFlushWriter) Flush() {
+	if !rw.wroteHeader {
+		rw.WriteHeader(http.StatusOK)
+	}
+
+	rw.ResponseWriter.(http.Flusher).Flush()
+}
+
+// static tests
+var _ http.Flusher = &recordingFlushWriter{}
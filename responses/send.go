@@ -4,9 +4,13 @@ import "net/http"
 
 // Send writes a JSON response body and sets the content type of the response
 func Send(w http.ResponseWriter, code int, data []byte) {
-	w.Header().Set("Content-Type", "application/json; charset=utf-8")
-	w.Header().Set("X-Content-Type-Options", "nosniff")
+	SendAs(w, code, "application/json; charset=utf-8", data)
+}
 
+// SendAs writes a response body with the given content type
+func SendAs(w http.ResponseWriter, code int, contentType string, data []byte) {
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
 	w.WriteHeader(code)
 	_, err := w.Write(data)
 	if err != nil {
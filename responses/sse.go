@@ -0,0 +1,151 @@
+package responses
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Event is a single server-sent event. Only Data is required.
+type Event struct {
+	ID    string
+	Event string
+	Data  string
+	Retry time.Duration // how long browsers should wait before reconnecting
+}
+
+// EventStream writes server-sent events to a response, flushing after every event.
+type EventStream struct {
+	mu     sync.Mutex
+	w      http.ResponseWriter
+	rc     *http.ResponseController
+	lastID string
+}
+
+// SSE starts an event stream by writing the headers of the response. It works through
+// the writers of ResponseTime and compression middleware as long as the writer
+// underneath supports flushing, otherwise it returns http.ErrNotSupported.
+func SSE(w http.ResponseWriter, r *http.Request) (*EventStream, error) {
+	rc := http.NewResponseController(w)
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no") // stop nginx from buffering events
+	w.Header().Del("Content-Length")
+	w.WriteHeader(http.StatusOK)
+
+	if err := rc.Flush(); err != nil {
+		return nil, err
+	}
+
+	// streams outlive the server's write timeout. We don't mind if it can't be changed
+	_ = rc.SetWriteDeadline(time.Time{})
+
+	return &EventStream{w: w, rc: rc, lastID: r.Header.Get("Last-Event-ID")}, nil
+}
+
+// LastEventID is the ID of the last event sent. Before any event is sent, it's the
+// Last-Event-ID header browsers send when reconnecting, so handlers can resume from it.
+func (s *EventStream) LastEventID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.lastID
+}
+
+// Send writes the event and flushes it to the client.
+func (s *EventStream) Send(e Event) error {
+	var buf bytes.Buffer
+
+	if e.ID != "" {
+		buf.WriteString("id: " + stripNewlines(e.ID) + "\n")
+	}
+
+	if e.Event != "" {
+		buf.WriteString("event: " + stripNewlines(e.Event) + "\n")
+	}
+
+	if e.Retry > 0 {
+		buf.WriteString("retry: " + strconv.FormatInt(e.Retry.Milliseconds(), 10) + "\n")
+	}
+
+	data := strings.ReplaceAll(e.Data, "\r\n", "\n")
+	for _, line := range strings.Split(data, "\n") {
+		buf.WriteString("data: " + line + "\n")
+	}
+	buf.WriteString("\n")
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.write(buf.Bytes()); err != nil {
+		return err
+	}
+
+	if e.ID != "" {
+		s.lastID = e.ID
+	}
+
+	return nil
+}
+
+// Heartbeat writes a comment to keep idle connections from being closed by proxies.
+func (s *EventStream) Heartbeat() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.write([]byte(":\n\n"))
+}
+
+// Stream sends events from the channel until it's closed or ctx is done, sending a
+// heartbeat whenever no event has been sent for the interval. It returns nil when the
+// client goes away or ctx times out(e.g. from requests.Timeout), and only returns errors
+// from writing to the client.
+func (s *EventStream) Stream(ctx context.Context, events <-chan Event, interval time.Duration) error {
+	var ticker *time.Ticker
+	var tick <-chan time.Time
+	if interval > 0 {
+		ticker = time.NewTicker(interval)
+		defer ticker.Stop()
+		tick = ticker.C
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case e, ok := <-events:
+			if !ok {
+				return nil
+			}
+
+			if err := s.Send(e); err != nil {
+				return err
+			}
+
+			if ticker != nil {
+				ticker.Reset(interval)
+			}
+		case <-tick:
+			if err := s.Heartbeat(); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+func (s *EventStream) write(buf []byte) error {
+	if _, err := s.w.Write(buf); err != nil {
+		return err
+	}
+
+	return s.rc.Flush()
+}
+
+func stripNewlines(s string) string {
+	return strings.NewReplacer("\r", "", "\n", "").Replace(s)
+}
@@ -0,0 +1,127 @@
+package responses
+
+import (
+	"bufio"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/noxecane/anansi/requests"
+)
+
+func TestSSE(t *testing.T) {
+	router := chi.NewRouter()
+	router.Use(middleware.Compress(5))
+	router.Use(requests.Timeout(300 * time.Millisecond))
+	router.Use(ResponseTime)
+
+	router.Get("/events", func(w http.ResponseWriter, r *http.Request) {
+		stream, err := SSE(w, r)
+		if err != nil {
+			t.Error(err)
+			return
+		}
+
+		events := make(chan Event, 2)
+		events <- Event{ID: "2", Event: "progress", Data: "50%\n60%", Retry: time.Second}
+		events <- Event{ID: stream.LastEventID() + "-next", Data: "resumed"}
+
+		if err := stream.Stream(r.Context(), events, 100*time.Millisecond); err != nil {
+			t.Error(err)
+		}
+	})
+
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	req, err := http.NewRequest("GET", server.URL+"/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept-Encoding", "gzip")
+	req.Header.Set("Last-Event-ID", "1")
+
+	start := time.Now()
+	res, err := http.DefaultTransport.RoundTrip(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer res.Body.Close()
+
+	if ct := res.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Expected the content type to be %s, got %s", "text/event-stream", ct)
+	}
+
+	if res.Header.Get(ResponseTimeHeader) == "" {
+		t.Errorf("Expected the %s header to be set", ResponseTimeHeader)
+	}
+
+	// read the first event as soon as it's flushed
+	reader := bufio.NewReader(res.Body)
+	var first []string
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if line == "\n" {
+			break
+		}
+		first = append(first, strings.TrimSuffix(line, "\n"))
+	}
+
+	expected := []string{"id: 2", "event: progress", "retry: 1000", "data: 50%", "data: 60%"}
+	if strings.Join(first, "|") != strings.Join(expected, "|") {
+		t.Errorf("Expected the first event to be %v, got %v", expected, first)
+	}
+
+	if time.Since(start) > 200*time.Millisecond {
+		t.Error("Expected the first event to be flushed immediately")
+	}
+
+	// the rest of the stream ends when requests.Timeout cancels the context
+	rest := new(strings.Builder)
+	if _, err := reader.WriteTo(rest); err != nil {
+		t.Fatal(err)
+	}
+
+	if !strings.Contains(rest.String(), "id: 1-next\ndata: resumed\n\n") {
+		t.Errorf("Expected the stream to resume from Last-Event-ID, got %q", rest.String())
+	}
+
+	if !strings.Contains(rest.String(), ":\n\n") {
+		t.Errorf("Expected the stream to have heartbeats, got %q", rest.String())
+	}
+}
+
+func TestSSEHeartbeats(t *testing.T) {
+	res := httptest.NewRecorder()
+	req := httptest.NewRequest("GET", "/events", nil)
+
+	stream, err := SSE(res, req)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	events := make(chan Event)
+	go func() {
+		defer close(events)
+		for range 10 {
+			time.Sleep(20 * time.Millisecond)
+			events <- Event{Data: "tick"}
+		}
+	}()
+
+	if err := stream.Stream(req.Context(), events, 60*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(res.Body.String(), ":\n\n") {
+		t.Errorf("Expected no heartbeats while events are flowing, got %q", res.Body.String())
+	}
+}
@@ -24,11 +24,20 @@ type TimedResponseWriter interface {
 	Duration() time.Duration
 }
 
+// SizedResponseWriter is a wrapper around the http.ResponseWriter that counts
+// the bytes written to the response body. The writers created by ResponseTime
+// and AccessLog implement it.
+type SizedResponseWriter interface {
+	http.ResponseWriter
+	Size() int
+}
+
 type timedWriter struct {
 	http.ResponseWriter
 	start       time.Time
 	code        int
 	duration    time.Duration
+	size        int
 	wroteHeader bool
 }
 
@@ -74,7 +83,9 @@ func (t *timedWriter) Write(buf []byte) (int, error) {
 	if !t.wroteHeader {
 		t.WriteHeader(http.StatusOK)
 	}
-	return t.ResponseWriter.Write(buf)
+	n, err := t.ResponseWriter.Write(buf)
+	t.size += n
+	return n, err
 }
 
 func (t *timedWriter) Duration() time.Duration {
@@ -85,6 +96,16 @@ func (t *timedWriter) Code() int {
 	return t.code
 }
 
+// Size is the number of bytes of the response body written so far
+func (t *timedWriter) Size() int {
+	return t.size
+}
+
+// Unwrap lets http.ResponseController reach the original writer
+func (t *timedWriter) Unwrap() http.ResponseWriter {
+	return t.ResponseWriter
+}
+
 type flushWriter struct {
 	timedWriter
 }
@@ -115,7 +136,9 @@ func (h1 *httpWriter) ReadFrom(r io.Reader) (int64, error) {
 	if !h1.wroteHeader {
 		h1.WriteHeader(http.StatusOK)
 	}
-	return rf.ReadFrom(r)
+	n, err := rf.ReadFrom(r)
+	h1.size += int(n)
+	return n, err
 }
 
 type http2Writer struct {
@@ -133,6 +156,7 @@ func (h2 *http2Writer) Flush() {
 }
 
 // static tests
+var _ SizedResponseWriter = &timedWriter{}
 var _ http.Flusher = &httpWriter{}
 var _ http.Flusher = &http2Writer{}
 
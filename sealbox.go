@@ -37,6 +37,11 @@ func Decrypt(secret []byte, encrypted string) ([]byte, error) {
 		return encBytes, err
 	}
 
+	// we can't even get the nonce
+	if len(encBytes) < 24 {
+		return nil, errors.New("could not decrypt your message")
+	}
+
 	// extract the nonce from message
 	var decryptNonce [24]byte
 	copy(decryptNonce[:], encBytes[:24])
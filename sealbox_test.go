@@ -45,4 +45,12 @@ func TestEncryptDecrypt(t *testing.T) {
 			t.Error("Expected Decrypt to fail for base64 encoded string")
 		}
 	})
+
+	t.Run("Decrypt fails for strings shorter than the nonce", func(t *testing.T) {
+		for _, short := range []string{"", "AAAA"} {
+			if _, err := Decrypt(secret, short); err == nil {
+				t.Errorf("Expected Decrypt to fail for %q", short)
+			}
+		}
+	})
 }
@@ -120,41 +120,35 @@ func (m *Manager) ToAuth(w http.ResponseWriter, token string, isHeadless bool) {
 
 // FromCookie loads a session from the request's cookie
 func (m *Manager) FromCookie(r *http.Request, v any) error {
-	ck, _ := r.Cookie(m.cookieKey)
-	if ck == nil {
-		return ErrEmptyAuthCookie
-	}
-	return m.store.Extend(r.Context(), ck.Value, m.cookieTimeout, v)
+	return m.fromCookie(r, v, true)
 }
 
 // FromAuth loads a session from the Authorization header (supports both bearer and headless)
 func (m *Manager) FromAuth(r *http.Request, v any) error {
-	scheme, token, err := getAuthorization(r)
-	if err != nil {
-		return err
-	}
-
-	switch scheme {
-	case "bearer":
-		return m.store.Extend(r.Context(), token, m.bearerTimeout, v)
-	case strings.ToLower(m.scheme):
-		return jwt.Decode(m.secret, token, v)
-	default:
-		return ErrUnsupportedScheme
-	}
+	return m.fromAuth(r, v, true)
 }
 
 // Load attempts to load a session from either Authorization header or cookie
 func (m *Manager) Load(r *http.Request, v any) error {
-	err := m.FromAuth(r, v)
+	return m.load(r, v, true)
+}
+
+// Peek is Load without extending the lifetime of the session. Use it for code
+// that looks at sessions without serving them, like rate limiting.
+func (m *Manager) Peek(r *http.Request, v any) error {
+	return m.load(r, v, false)
+}
+
+func (m *Manager) load(r *http.Request, v any, extend bool) error {
+	err := m.fromAuth(r, v, extend)
 	switch err {
 	case ErrEmptyHeader:
-		return m.FromCookie(r, v)
+		return m.fromCookie(r, v, extend)
 	case nil:
 		return nil
 	default:
 		// Try cookie fallback for other auth errors
-		if cookieErr := m.FromCookie(r, v); cookieErr == nil {
+		if cookieErr := m.fromCookie(r, v, extend); cookieErr == nil {
 			return nil
 		}
 		// Return original auth error if cookie also fails
@@ -162,6 +156,39 @@ func (m *Manager) Load(r *http.Request, v any) error {
 	}
 }
 
+func (m *Manager) fromCookie(r *http.Request, v any, extend bool) error {
+	ck, _ := r.Cookie(m.cookieKey)
+	if ck == nil {
+		return ErrEmptyAuthCookie
+	}
+
+	if !extend {
+		return m.store.Peek(r.Context(), ck.Value, v)
+	}
+
+	return m.store.Extend(r.Context(), ck.Value, m.cookieTimeout, v)
+}
+
+func (m *Manager) fromAuth(r *http.Request, v any, extend bool) error {
+	scheme, token, err := getAuthorization(r)
+	if err != nil {
+		return err
+	}
+
+	switch scheme {
+	case "bearer":
+		if !extend {
+			return m.store.Peek(r.Context(), token, v)
+		}
+
+		return m.store.Extend(r.Context(), token, m.bearerTimeout, v)
+	case strings.ToLower(m.scheme):
+		return jwt.DecodeContext(r.Context(), m.secret, token, v)
+	default:
+		return ErrUnsupportedScheme
+	}
+}
+
 // LogoutCookie clears the authentication cookie
 func (m *Manager) LogoutCookie(r *http.Request, w http.ResponseWriter) error {
 	ck, _ := r.Cookie(m.cookieKey)
@@ -187,17 +214,22 @@ func (m *Manager) LogoutCookie(r *http.Request, w http.ResponseWriter) error {
 	return nil
 }
 
-// LogoutAuth revokes a session token from Authorization header
+// LogoutAuth revokes a session token from Authorization header. Headless tokens
+// are only revoked when jwt.Revocations is set.
 func (m *Manager) LogoutAuth(r *http.Request) error {
 	scheme, token, err := getAuthorization(r)
 	if err != nil {
 		return err
 	}
-	// Only revoke stateful tokens (bearer), headless tokens are stateless
-	if scheme == "bearer" {
+
+	switch scheme {
+	case "bearer":
 		return m.store.Decommission(r.Context(), token, &ClearCookie)
+	case strings.ToLower(m.scheme):
+		return jwt.Revoke(r.Context(), m.secret, token)
+	default:
+		return nil
 	}
-	return nil // Headless tokens don't need revocation
 }
 
 func getAuthorization(r *http.Request) (string, string, error) {
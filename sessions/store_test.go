@@ -332,6 +332,31 @@ func TestLogoutAuth(t *testing.T) {
 		}
 	})
 
+	t.Run("revokes headless tokens when revocations are configured", func(t *testing.T) {
+		jwt.Revocations = tokens.NewMemoryRevocationList()
+		defer func() { jwt.Revocations = nil }()
+
+		token, err := manager.NewHeadlessSession(session{"Headless"})
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		logoutReq := httptest.NewRequest("POST", "/logout", nil)
+		logoutReq.Header.Set("Authorization", scheme+" "+token)
+
+		if err := manager.LogoutAuth(logoutReq); err != nil {
+			t.Fatal(err)
+		}
+
+		loadReq := httptest.NewRequest("GET", "/test", nil)
+		loadReq.Header.Set("Authorization", scheme+" "+token)
+
+		err = manager.FromAuth(loadReq, &session{})
+		if err != jwt.ErrJWTRevoked {
+			t.Errorf("Expected error to be ErrJWTRevoked, got %v", err)
+		}
+	})
+
 	t.Run("fails when no authorization header is present", func(t *testing.T) {
 		req := httptest.NewRequest("POST", "/logout", nil)
 
@@ -359,3 +384,38 @@ func TestLogoutAuth(t *testing.T) {
 		}
 	})
 }
+
+func TestPeek(t *testing.T) {
+	type session struct {
+		Name string
+	}
+
+	manager := NewManager(sharedTestStore, secret, Config{BearerDuration: time.Hour, HeadlessScheme: scheme})
+	defer flushRedis(context.TODO(), t)
+
+	token, err := sharedTestStore.Commission(context.TODO(), time.Minute, "key", session{"Premium"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	req := httptest.NewRequest("GET", "/entities", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	var s session
+	if err := manager.Peek(req, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.Name != "Premium" {
+		t.Errorf(`Expected name in session to be "%s", got %s`, "Premium", s.Name)
+	}
+
+	ttl, err := client.TTL(context.TODO(), token).Result()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if ttl > time.Minute {
+		t.Errorf("Expected the session to expire within %s, got %s", time.Minute, ttl)
+	}
+}
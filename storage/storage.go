@@ -0,0 +1,159 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrNotFound   = errors.New("no file has been saved with the key")
+	ErrInvalidKey = errors.New("keys must be relative paths without \"..\"")
+)
+
+// Storage saves files by their keys.
+type Storage interface {
+	// Save writes everything from r under the key, replacing any existing file, and
+	// returns the number of bytes written. Nothing is saved if reading r fails.
+	Save(ctx context.Context, key string, r io.Reader) (int64, error)
+	// Open reads the file saved with the key.
+	Open(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete removes the file saved with the key.
+	Delete(ctx context.Context, key string) error
+}
+
+type disk struct {
+	root string
+}
+
+// NewDisk creates a Storage that saves files under the root directory of the local
+// filesystem, creating it if it doesn't exist.
+func NewDisk(root string) (Storage, error) {
+	if err := os.MkdirAll(root, 0o755); err != nil {
+		return nil, err
+	}
+
+	return &disk{root: root}, nil
+}
+
+func (d *disk) Save(_ context.Context, key string, r io.Reader) (int64, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return 0, err
+	}
+
+	// write to a temporary file so readers never see partial files
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".upload-*")
+	if err != nil {
+		return 0, err
+	}
+	defer os.Remove(tmp.Name())
+
+	n, err := io.Copy(tmp, r)
+	if err != nil {
+		tmp.Close()
+		return 0, err
+	}
+
+	if err := tmp.Close(); err != nil {
+		return 0, err
+	}
+
+	return n, os.Rename(tmp.Name(), path)
+}
+
+func (d *disk) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := d.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+
+	return f, err
+}
+
+func (d *disk) Delete(_ context.Context, key string) error {
+	path, err := d.path(key)
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return ErrNotFound
+	}
+
+	return err
+}
+
+// path converts the key into a path within the root
+func (d *disk) path(key string) (string, error) {
+	clean := filepath.Clean(filepath.FromSlash(key))
+	if clean == "." || filepath.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, ".."+string(filepath.Separator)) {
+		return "", ErrInvalidKey
+	}
+
+	return filepath.Join(d.root, clean), nil
+}
+
+type memory struct {
+	mu    sync.RWMutex
+	files map[string][]byte
+}
+
+// NewMemory creates a Storage that keeps files in the memory of the current process.
+// Only use it for tests.
+func NewMemory() Storage {
+	return &memory{files: make(map[string][]byte)}
+}
+
+func (m *memory) Save(_ context.Context, key string, r io.Reader) (int64, error) {
+	content, err := io.ReadAll(r)
+	if err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.files[key] = content
+
+	return int64(len(content)), nil
+}
+
+func (m *memory) Open(_ context.Context, key string) (io.ReadCloser, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	content, ok := m.files[key]
+	if !ok {
+		return nil, ErrNotFound
+	}
+
+	return io.NopCloser(bytes.NewReader(content)), nil
+}
+
+func (m *memory) Delete(_ context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.files[key]; !ok {
+		return ErrNotFound
+	}
+	delete(m.files, key)
+
+	return nil
+}
@@ -0,0 +1,81 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStorage(t *testing.T) {
+	ctx := context.TODO()
+
+	disk, err := NewDisk(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stores := map[string]Storage{
+		"disk":   disk,
+		"memory": NewMemory(),
+	}
+
+	for name, store := range stores {
+		t.Run(name+" saves and opens files", func(t *testing.T) {
+			n, err := store.Save(ctx, "books/cover.txt", strings.NewReader("things fall apart"))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if n != 17 {
+				t.Errorf("Expected %d bytes to be written, got %d", 17, n)
+			}
+
+			f, err := store.Open(ctx, "books/cover.txt")
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer f.Close()
+
+			content, _ := io.ReadAll(f)
+			if string(content) != "things fall apart" {
+				t.Errorf("Expected the content to be %s, got %s", "things fall apart", content)
+			}
+		})
+
+		t.Run(name+" saves nothing when reading fails", func(t *testing.T) {
+			r := io.MultiReader(strings.NewReader("partial"), iotest.ErrReader(errors.New("broken")))
+			if _, err := store.Save(ctx, "broken.txt", r); err == nil {
+				t.Fatal("Expected Save to fail")
+			}
+
+			if _, err := store.Open(ctx, "broken.txt"); err != ErrNotFound {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+		})
+
+		t.Run(name+" deletes files", func(t *testing.T) {
+			if _, err := store.Save(ctx, "delete.txt", strings.NewReader("gone")); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.Delete(ctx, "delete.txt"); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.Delete(ctx, "delete.txt"); err != ErrNotFound {
+				t.Errorf("Expected ErrNotFound, got %v", err)
+			}
+		})
+	}
+
+	t.Run("disk rejects keys outside its root", func(t *testing.T) {
+		for _, key := range []string{"../escape.txt", "/etc/passwd", "books/../../escape.txt"} {
+			if _, err := disk.Save(ctx, key, strings.NewReader("")); err != ErrInvalidKey {
+				t.Errorf("Expected ErrInvalidKey for %s, got %v", key, err)
+			}
+		}
+	})
+}
@@ -0,0 +1,104 @@
+package tokens
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/noxecane/anansi/internal/expiring"
+	"github.com/redis/go-redis/v9"
+)
+
+// IdempotencyPrefix namespaces the idempotency keys NewIdempotencyStore saves in redis.
+var IdempotencyPrefix = "anansi_idempotency:"
+
+// IdempotencyStore keeps the responses of requests made with an idempotency key, so
+// retries of the same request can be replayed rather than processed again.
+type IdempotencyStore interface {
+	// Reserve saves v under the key only if the key isn't in use. It returns false
+	// along with the value already saved if it is.
+	Reserve(ctx context.Context, key string, v []byte, t time.Duration) (bool, []byte, error)
+	// Save replaces the value of a key, resetting its TTL.
+	Save(ctx context.Context, key string, v []byte, t time.Duration) error
+	// Release frees up the key for use by another request.
+	Release(ctx context.Context, key string) error
+}
+
+type redisIdempotency struct {
+	redis *redis.Client
+}
+
+// NewIdempotencyStore creates an IdempotencyStore that keeps responses in redis, so
+// a retry is replayed whichever instance it lands on. Keys are reserved with SETNX,
+// which makes sure only one of concurrent requests with the same key gets processed.
+func NewIdempotencyStore(r *redis.Client) IdempotencyStore {
+	return &redisIdempotency{redis: r}
+}
+
+func (is *redisIdempotency) Reserve(ctx context.Context, key string, v []byte, t time.Duration) (bool, []byte, error) {
+	for {
+		ok, err := is.redis.SetNX(ctx, IdempotencyPrefix+key, v, t).Result()
+		if err != nil || ok {
+			return ok, nil, err
+		}
+
+		saved, err := is.redis.Get(ctx, IdempotencyPrefix+key).Bytes()
+		if err == redis.Nil {
+			// the key expired right after SETNX, try again
+			continue
+		}
+
+		return false, saved, err
+	}
+}
+
+func (is *redisIdempotency) Save(ctx context.Context, key string, v []byte, t time.Duration) error {
+	return is.redis.Set(ctx, IdempotencyPrefix+key, v, t).Err()
+}
+
+func (is *redisIdempotency) Release(ctx context.Context, key string) error {
+	return is.redis.Del(ctx, IdempotencyPrefix+key).Err()
+}
+
+type memoryIdempotency struct {
+	mu      sync.Mutex
+	entries *expiring.Map[[]byte]
+}
+
+// NewMemoryIdempotencyStore creates an IdempotencyStore that keeps responses in memory.
+// Retries are only replayed if they reach the same process, and all keys are lost on restart.
+func NewMemoryIdempotencyStore() IdempotencyStore {
+	return &memoryIdempotency{entries: expiring.New[[]byte](time.Minute)}
+}
+
+func (is *memoryIdempotency) Reserve(_ context.Context, key string, v []byte, t time.Duration) (bool, []byte, error) {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	now := time.Now()
+	if saved, ok := is.entries.Get(key, now); ok {
+		return false, saved, nil
+	}
+
+	is.entries.Set(key, v, now, t)
+
+	return true, nil, nil
+}
+
+func (is *memoryIdempotency) Save(_ context.Context, key string, v []byte, t time.Duration) error {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	is.entries.Set(key, v, time.Now(), t)
+
+	return nil
+}
+
+func (is *memoryIdempotency) Release(_ context.Context, key string) error {
+	is.mu.Lock()
+	defer is.mu.Unlock()
+
+	is.entries.Delete(key)
+
+	return nil
+}
@@ -0,0 +1,91 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	stores := map[string]IdempotencyStore{
+		"redis":  NewIdempotencyStore(client),
+		"memory": NewMemoryIdempotencyStore(),
+	}
+
+	for name, store := range stores {
+		t.Run(name+" reserves keys once", func(t *testing.T) {
+			defer flushRedis(t)
+
+			key := ksuid.New().String()
+
+			ok, _, err := store.Reserve(ctx, key, []byte("first"), time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !ok {
+				t.Fatalf("Expected %s to be reserved", key)
+			}
+
+			ok, saved, err := store.Reserve(ctx, key, []byte("second"), time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if ok {
+				t.Errorf("Expected %s not to be reserved twice", key)
+			}
+
+			if string(saved) != "first" {
+				t.Errorf("Expected the saved value to be %s, got %s", "first", saved)
+			}
+		})
+
+		t.Run(name+" replaces saved values", func(t *testing.T) {
+			defer flushRedis(t)
+
+			key := ksuid.New().String()
+
+			if _, _, err := store.Reserve(ctx, key, []byte("pending"), time.Minute); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.Save(ctx, key, []byte("done"), time.Minute); err != nil {
+				t.Fatal(err)
+			}
+
+			_, saved, err := store.Reserve(ctx, key, []byte("pending"), time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(saved) != "done" {
+				t.Errorf("Expected the saved value to be %s, got %s", "done", saved)
+			}
+		})
+
+		t.Run(name+" releases keys", func(t *testing.T) {
+			defer flushRedis(t)
+
+			key := ksuid.New().String()
+
+			if _, _, err := store.Reserve(ctx, key, []byte("pending"), time.Minute); err != nil {
+				t.Fatal(err)
+			}
+
+			if err := store.Release(ctx, key); err != nil {
+				t.Fatal(err)
+			}
+
+			ok, _, err := store.Reserve(ctx, key, []byte("pending"), time.Minute)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !ok {
+				t.Errorf("Expected %s to be reserved after release", key)
+			}
+		})
+	}
+}
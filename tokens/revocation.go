@@ -0,0 +1,84 @@
+package tokens
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/noxecane/anansi/internal/expiring"
+	"github.com/redis/go-redis/v9"
+)
+
+// RevocationPrefix namespaces the token IDs NewRevocationList saves in redis.
+var RevocationPrefix = "anansi_revoked:"
+
+// RevocationList keeps track of stateless tokens(by their IDs) that should no
+// longer be accepted even though they haven't expired.
+type RevocationList interface {
+	// Revoke marks the token ID as revoked for the given duration. The duration should
+	// match how long the token has left before it expires.
+	Revoke(ctx context.Context, id string, t time.Duration) error
+	// IsRevoked checks if the token ID has been revoked.
+	IsRevoked(ctx context.Context, id string) (bool, error)
+}
+
+type redisRevocations struct {
+	redis *redis.Client
+}
+
+// NewRevocationList creates a RevocationList that saves revoked IDs in redis until
+// their tokens would have expired, so a token revoked through one instance is
+// rejected by all of them.
+func NewRevocationList(r *redis.Client) RevocationList {
+	return &redisRevocations{redis: r}
+}
+
+func (rl *redisRevocations) Revoke(ctx context.Context, id string, t time.Duration) error {
+	if t <= 0 {
+		return nil
+	}
+
+	return rl.redis.Set(ctx, RevocationPrefix+id, 1, t).Err()
+}
+
+func (rl *redisRevocations) IsRevoked(ctx context.Context, id string) (bool, error) {
+	n, err := rl.redis.Exists(ctx, RevocationPrefix+id).Result()
+	if err != nil {
+		return false, err
+	}
+
+	return n > 0, nil
+}
+
+type memoryRevocations struct {
+	mu      sync.Mutex
+	revoked *expiring.Map[struct{}]
+}
+
+// NewMemoryRevocationList creates a RevocationList that keeps revoked IDs in memory.
+// Revocations are forgotten on restart and other instances keep accepting the tokens.
+func NewMemoryRevocationList() RevocationList {
+	return &memoryRevocations{revoked: expiring.New[struct{}](time.Minute)}
+}
+
+func (rl *memoryRevocations) Revoke(_ context.Context, id string, t time.Duration) error {
+	if t <= 0 {
+		return nil
+	}
+
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	rl.revoked.Set(id, struct{}{}, time.Now(), t)
+
+	return nil
+}
+
+func (rl *memoryRevocations) IsRevoked(_ context.Context, id string) (bool, error) {
+	rl.mu.Lock()
+	defer rl.mu.Unlock()
+
+	_, ok := rl.revoked.Get(id, time.Now())
+
+	return ok, nil
+}
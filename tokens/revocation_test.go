@@ -0,0 +1,52 @@
+package tokens
+
+import (
+	"testing"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+func TestRevocationList(t *testing.T) {
+	lists := map[string]RevocationList{
+		"redis":  NewRevocationList(client),
+		"memory": NewMemoryRevocationList(),
+	}
+
+	for name, list := range lists {
+		t.Run(name+" revokes IDs until they expire", func(t *testing.T) {
+			defer flushRedis(t)
+
+			id := ksuid.New().String()
+			if err := list.Revoke(ctx, id, time.Second); err != nil {
+				t.Fatal(err)
+			}
+
+			revoked, err := list.IsRevoked(ctx, id)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if !revoked {
+				t.Errorf("Expected %s to be revoked", id)
+			}
+
+			time.Sleep(time.Millisecond * 1100)
+
+			if revoked, _ = list.IsRevoked(ctx, id); revoked {
+				t.Errorf("Expected revocation of %s to expire", id)
+			}
+		})
+
+		t.Run(name+" ignores unknown IDs", func(t *testing.T) {
+			revoked, err := list.IsRevoked(ctx, ksuid.New().String())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if revoked {
+				t.Error("Expected unknown ID not to be revoked")
+			}
+		})
+	}
+}
@@ -1,11 +1,13 @@
 package webpack
 
 import (
+	"net/http"
 	"time"
 
 	"github.com/go-chi/chi/v5"
 	"github.com/go-chi/chi/v5/middleware"
 	"github.com/noxecane/anansi/api"
+	"github.com/noxecane/anansi/ratelimit"
 	"github.com/noxecane/anansi/requests"
 	"github.com/noxecane/anansi/responses"
 	"github.com/prometheus/client_golang/prometheus"
@@ -14,11 +16,18 @@ import (
 
 // WebpackOpts are configuration values for the Webpack middleware
 type WebpackOpts struct {
-	Environment      string                // Application environment(dev, test e.t.c.)
-	Timeout          time.Duration         // Duration before request context times out. Defaults to 1 minute
-	CompressionLevel int                   // Level of compression for responses, ranging from 1-9. Defaults to 5
-	CORSOrigins      []string              // list of allowed origins
-	Registry         prometheus.Registerer // registry for prometheus. This is where we add response time collector
+	Environment      string                     // Application environment(dev, test e.t.c.)
+	Timeout          time.Duration              // Duration before request context times out. Defaults to 1 minute
+	CompressionLevel int                        // Level of compression for responses, ranging from 1-9. Defaults to 5
+	CORSOrigins      []string                   // list of allowed origins
+	CORS             *requests.CORSOptions      // full CORS configuration, used instead of CORSOrigins
+	Registry         prometheus.Registerer      // registry for prometheus. This is where we add response time collector
+	RateLimiter      ratelimit.Limiter          // optional limiter applied to every request
+	RateLimitKey     ratelimit.KeyFunc          // identifies clients for the RateLimiter. Defaults to ratelimit.ByIP
+	Decoding         requests.DecodeOptions     // limits on size and strictness of request bodies
+	LogPolicy        *requests.LogPolicy        // which requests get logged. Defaults to requests.LogDefaults
+	AccessLog        bool                       // log a single line once each request completes
+	AccessLogUser    func(*http.Request) string // identifies the user or session in access logs
 }
 
 // Webpack sets a reasonable set of middleware in the right order taking into consideration
@@ -26,6 +35,8 @@ type WebpackOpts struct {
 //
 // The middleware set up includes:
 //
+// - CORS handling for dev and production(if origins or options are set)
+//
 // - Automatic Request IDs
 //
 // - Response time middleware and header(and metrics if a registry is passed)
@@ -36,18 +47,16 @@ type WebpackOpts struct {
 //
 // - Compressing response body
 //
-// - CORS handling for dev and production
+// - Request body limits(if any is set)
 //
-// - Request Logging
+// - Request Logging(and access logs if enabled)
 //
 // - Panic Recovery(with special support for api.Error)
 //
 // - Timeouts on request context
+//
+// - Rate limiting(if a limiter is passed)
 func Webpack(router *chi.Mux, log zerolog.Logger, conf WebpackOpts) {
-	if len(conf.CORSOrigins) > 0 {
-		requests.CORS(conf.Environment, conf.CORSOrigins...)
-	}
-
 	if conf.CompressionLevel == 0 {
 		conf.CompressionLevel = 5
 	}
@@ -56,14 +65,29 @@ func Webpack(router *chi.Mux, log zerolog.Logger, conf WebpackOpts) {
 		conf.Timeout = time.Minute
 	}
 
+	// answer preflight requests before any other work is done
+	if cors := corsMiddleware(conf); cors != nil {
+		router.Use(cors)
+	}
+
 	router.Use(middleware.Compress(conf.CompressionLevel))
 
 	router.Use(middleware.RequestID)
 	router.Use(middleware.RealIP)
 	router.Use(middleware.RedirectSlashes)
 
+	if conf.Decoding != (requests.DecodeOptions{}) {
+		router.Use(requests.Decoding(conf.Decoding))
+	}
+
 	router.Use(requests.AttachLogger(log))
+	if conf.LogPolicy != nil {
+		router.Use(requests.Logging(*conf.LogPolicy))
+	}
 	router.Use(requests.Log)
+	if conf.AccessLog {
+		router.Use(responses.AccessLog(conf.AccessLogUser))
+	}
 	router.Use(requests.Timeout(conf.Timeout))
 
 	router.Use(responses.ResponseTime)
@@ -71,6 +95,13 @@ func Webpack(router *chi.Mux, log zerolog.Logger, conf WebpackOpts) {
 		router.Use(responses.RequestDuration(conf.Registry))
 	}
 	router.Use(api.Recoverer(conf.Environment))
+
+	if conf.RateLimiter != nil {
+		if conf.RateLimitKey == nil {
+			conf.RateLimitKey = ratelimit.ByIP
+		}
+		router.Use(ratelimit.Limit(conf.RateLimiter, conf.RateLimitKey))
+	}
 }
 
 // HTMLPack sets a reasonable set of middleware in the right order taking into consideration
@@ -78,13 +109,15 @@ func Webpack(router *chi.Mux, log zerolog.Logger, conf WebpackOpts) {
 //
 // The middleware set up includes:
 //
+// - CORS handling for dev and production(if origins or options are set)
+//
 // - Automatic Request IDs
 //
 // - Real IP middleware
 //
 // - Compressing response body
 //
-// - Request Logging
+// - Request Logging(and access logs if enabled)
 //
 // - Response time header
 //
@@ -100,14 +133,39 @@ func HTMLpack(router *chi.Mux, log zerolog.Logger, conf WebpackOpts) {
 		conf.Timeout = time.Minute
 	}
 
+	// answer preflight requests before any other work is done
+	if cors := corsMiddleware(conf); cors != nil {
+		router.Use(cors)
+	}
+
 	router.Use(middleware.Compress(conf.CompressionLevel))
 
 	router.Use(middleware.RequestID)
 	router.Use(middleware.RealIP)
 	router.Use(requests.AttachLogger(log))
+	if conf.LogPolicy != nil {
+		router.Use(requests.Logging(*conf.LogPolicy))
+	}
 	router.Use(requests.Log)
+	if conf.AccessLog {
+		router.Use(responses.AccessLog(conf.AccessLogUser))
+	}
 	router.Use(requests.Timeout(conf.Timeout))
 
 	router.Use(responses.ResponseTime)
 	router.Use(api.Recoverer(conf.Environment))
 }
+
+// corsMiddleware picks the CORS middleware for conf, returning nil if it has no CORS
+// configuration.
+func corsMiddleware(conf WebpackOpts) func(http.Handler) http.Handler {
+	if conf.CORS != nil {
+		return requests.CORSWith(*conf.CORS)
+	}
+
+	if len(conf.CORSOrigins) > 0 {
+		return requests.CORS(conf.Environment, conf.CORSOrigins...)
+	}
+
+	return nil
+}
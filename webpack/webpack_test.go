@@ -0,0 +1,121 @@
+package webpack
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/noxecane/anansi/requests"
+	"github.com/rs/zerolog"
+)
+
+// requireAuth stands in for session middleware like api.Headless
+func requireAuth(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") == "" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func preflight(router http.Handler, origin string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest("OPTIONS", "/books", nil)
+	req.Header.Set("Origin", origin)
+	req.Header.Set("Access-Control-Request-Method", "POST")
+	req.Header.Set("Access-Control-Request-Headers", "Authorization, Content-Type")
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	return res
+}
+
+func TestWebpackCORS(t *testing.T) {
+	router := chi.NewRouter()
+	Webpack(router, zerolog.New(io.Discard), WebpackOpts{
+		Environment: "production",
+		CORSOrigins: []string{"https://app.example.com"},
+	})
+	router.With(requireAuth).Post("/books", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusCreated)
+	})
+
+	t.Run("answers preflight requests before auth", func(t *testing.T) {
+		res := preflight(router, "https://app.example.com")
+
+		if res.Code != http.StatusOK && res.Code != http.StatusNoContent {
+			t.Errorf("Expected a successful preflight, got %d", res.Code)
+		}
+
+		if origin := res.Header().Get("Access-Control-Allow-Origin"); origin != "https://app.example.com" {
+			t.Errorf("Expected Access-Control-Allow-Origin to be https://app.example.com, got %s", origin)
+		}
+
+		if methods := res.Header().Get("Access-Control-Allow-Methods"); methods != "POST" {
+			t.Errorf("Expected Access-Control-Allow-Methods to be POST, got %s", methods)
+		}
+
+		if creds := res.Header().Get("Access-Control-Allow-Credentials"); creds != "true" {
+			t.Errorf("Expected Access-Control-Allow-Credentials to be true, got %s", creds)
+		}
+
+		if encoding := res.Header().Get("Content-Encoding"); encoding != "" {
+			t.Errorf("Expected preflight responses not to be compressed, got %s", encoding)
+		}
+	})
+
+	t.Run("rejects unknown origins", func(t *testing.T) {
+		res := preflight(router, "https://evil.example.net")
+
+		if origin := res.Header().Get("Access-Control-Allow-Origin"); origin != "" {
+			t.Errorf("Expected no Access-Control-Allow-Origin, got %s", origin)
+		}
+	})
+
+	t.Run("adds CORS headers to actual requests", func(t *testing.T) {
+		req := httptest.NewRequest("POST", "/books", nil)
+		req.Header.Set("Origin", "https://app.example.com")
+		req.Header.Set("Authorization", "Bearer token")
+		res := httptest.NewRecorder()
+		router.ServeHTTP(res, req)
+
+		if res.Code != http.StatusCreated {
+			t.Errorf("Expected the status code to be %d, got %d", http.StatusCreated, res.Code)
+		}
+
+		if origin := res.Header().Get("Access-Control-Allow-Origin"); origin != "https://app.example.com" {
+			t.Errorf("Expected Access-Control-Allow-Origin to be https://app.example.com, got %s", origin)
+		}
+	})
+}
+
+func TestHTMLpackCORS(t *testing.T) {
+	router := chi.NewRouter()
+	HTMLpack(router, zerolog.New(io.Discard), WebpackOpts{
+		CORS: &requests.CORSOptions{
+			AllowedOrigins: []string{"https://*.example.com"},
+			AllowedHeaders: []string{"Authorization", "Content-Type"},
+		},
+	})
+	router.With(requireAuth).Post("/books", func(w http.ResponseWriter, r *http.Request) {})
+
+	res := preflight(router, "https://www.example.com")
+
+	if res.Code != http.StatusOK && res.Code != http.StatusNoContent {
+		t.Errorf("Expected a successful preflight, got %d", res.Code)
+	}
+
+	if origin := res.Header().Get("Access-Control-Allow-Origin"); origin != "https://www.example.com" {
+		t.Errorf("Expected Access-Control-Allow-Origin to be https://www.example.com, got %s", origin)
+	}
+
+	if headers := res.Header().Get("Access-Control-Allow-Headers"); headers != "Authorization, Content-Type" {
+		t.Errorf("Expected Access-Control-Allow-Headers to be Authorization, Content-Type, got %s", headers)
+	}
+}